@@ -0,0 +1,73 @@
+package accessor
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"fmt"
+
+	"github.com/Ashik80/oauth2jwtgen/manager"
+)
+
+// Returns the accessor that signs with the key kid of any manager. The kind of accessor is
+// chosen from the signing key of the manager, so custom managers need no accessor of their own.
+func NewAccess(kid string, m manager.Manager) (JWTAccess, error) {
+	method, err := m.GetSigningMethod(kid)
+	if err != nil {
+		return nil, err
+	}
+	signingKey, err := m.GetSigningKey(kid)
+	if err != nil {
+		return nil, err
+	}
+
+	// The PEM is only kept for GetSigningKey, tokens are signed with the parsed key
+	pem, _ := m.GetKey(kid)
+
+	switch key := signingKey.(type) {
+	case []byte:
+		return &HSAccess{
+			SigningKeyID:  kid,
+			SigningKey:    key,
+			SigningMethod: method,
+		}, nil
+	case *rsa.PrivateKey:
+		return &RSAccess{
+			SignedKeyID:   kid,
+			SignedKey:     pem,
+			SigningMethod: method,
+			privateKey:    key,
+		}, nil
+	case *ecdsa.PrivateKey:
+		return &ESAccess{
+			SigningKeyID:  kid,
+			SigningKey:    pem,
+			SigningMethod: method,
+			privateKey:    key,
+		}, nil
+	case ed25519.PrivateKey:
+		return &EdDSAAccess{
+			SigningKeyID:  kid,
+			SigningKey:    pem,
+			SigningMethod: method,
+			privateKey:    key,
+		}, nil
+	case crypto.Signer:
+		info, err := m.GetKeyInfo(kid)
+		if err != nil {
+			return nil, err
+		}
+		publicKey := info.PublicKey
+		if publicKey == nil {
+			publicKey = key.Public()
+		}
+		return &SignerAccess{
+			SigningKeyID:  kid,
+			Signer:        key,
+			PublicKey:     publicKey,
+			SigningMethod: &SignerSigningMethod{SigningMethod: method},
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported signing key type %T", signingKey)
+}
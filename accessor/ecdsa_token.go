@@ -0,0 +1,67 @@
+package accessor
+
+import (
+	"context"
+
+	"github.com/Ashik80/oauth2jwtgen/manager"
+	"github.com/Ashik80/oauth2jwtgen/options"
+	"github.com/Ashik80/oauth2jwtgen/verifier"
+
+	"github.com/golang-jwt/jwt"
+)
+
+type ESAccess struct {
+	SigningKeyID  string
+	SigningKey    []byte
+	SigningMethod jwt.SigningMethod
+	privateKey    interface{}
+}
+
+// The signing method (ES256, ES384 or ES512) is chosen from the curve of the key
+func NewESAccess(kid string, manager *manager.ESKeyManager) (*ESAccess, error) {
+	key, err := manager.GetKey(kid)
+	if err != nil {
+		return nil, err
+	}
+	method, err := manager.GetSigningMethod(kid)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, err := manager.GetPrivateKey(kid)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &ESAccess{
+		SigningKeyID:  kid,
+		SigningKey:    key,
+		SigningMethod: method,
+		privateKey:    privateKey,
+	}
+
+	return e, nil
+}
+
+func (e *ESAccess) GetSigningKeyID() string {
+	return e.SigningKeyID
+}
+
+func (e *ESAccess) GetSigningKey() []byte {
+	return e.SigningKey
+}
+
+func (e *ESAccess) GetSigningMethod() jwt.SigningMethod {
+	return e.SigningMethod
+}
+
+func (e *ESAccess) parsedSigningKey() interface{} {
+	return e.privateKey
+}
+
+func (e *ESAccess) RenewToken(ctx context.Context, refreshToken string, signingKey string, opt *options.AuthOptions) (*Token, error) {
+	publicKey, err := verifier.LoadECPublicKeyFromFile(signingKey)
+	if err != nil {
+		return nil, err
+	}
+	return RenewToken(ctx, e, refreshToken, publicKey, opt)
+}
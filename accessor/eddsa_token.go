@@ -0,0 +1,62 @@
+package accessor
+
+import (
+	"context"
+
+	"github.com/Ashik80/oauth2jwtgen/manager"
+	"github.com/Ashik80/oauth2jwtgen/options"
+	"github.com/Ashik80/oauth2jwtgen/verifier"
+
+	"github.com/golang-jwt/jwt"
+)
+
+type EdDSAAccess struct {
+	SigningKeyID  string
+	SigningKey    []byte
+	SigningMethod jwt.SigningMethod
+	privateKey    interface{}
+}
+
+func NewEdDSAAccess(kid string, manager *manager.EdKeyManager) (*EdDSAAccess, error) {
+	key, err := manager.GetKey(kid)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, err := manager.GetPrivateKey(kid)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &EdDSAAccess{
+		SigningKeyID:  kid,
+		SigningKey:    key,
+		SigningMethod: jwt.SigningMethodEdDSA,
+		privateKey:    privateKey,
+	}
+
+	return e, nil
+}
+
+func (e *EdDSAAccess) GetSigningKeyID() string {
+	return e.SigningKeyID
+}
+
+func (e *EdDSAAccess) GetSigningKey() []byte {
+	return e.SigningKey
+}
+
+func (e *EdDSAAccess) GetSigningMethod() jwt.SigningMethod {
+	return e.SigningMethod
+}
+
+func (e *EdDSAAccess) parsedSigningKey() interface{} {
+	return e.privateKey
+}
+
+func (e *EdDSAAccess) RenewToken(ctx context.Context, refreshToken string, signingKey string, opt *options.AuthOptions) (*Token, error) {
+	publicKey, err := verifier.LoadEdPublicKeyFromFile(signingKey)
+	if err != nil {
+		return nil, err
+	}
+	return RenewToken(ctx, e, refreshToken, publicKey, opt)
+}
@@ -2,12 +2,17 @@ package accessor
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/Ashik80/oauth2jwtgen/claims"
+	"github.com/Ashik80/oauth2jwtgen/keyutil"
 	"github.com/Ashik80/oauth2jwtgen/options"
 	"github.com/Ashik80/oauth2jwtgen/store"
 	"github.com/golang-jwt/jwt"
@@ -22,6 +27,8 @@ type Token struct {
 	ExpiresIn    int64  `json:"expires_in,omitempty"`
 }
 
+var ErrInvalidRefreshToken = errors.New("invalid refresh token")
+
 type JWTAccess interface {
 	GetSigningKeyID() string
 	GetSigningKey() []byte
@@ -67,22 +74,43 @@ func NewToken(ctx context.Context, a JWTAccess, c *claims.JWTClaims, opt *option
 	return tok, nil
 }
 
+// Implemented by the accessors that hold the key parsed by their manager
+type parsedKeyAccess interface {
+	parsedSigningKey() interface{}
+}
+
+// Returns the key that signs the tokens of the accessor. Accessors created from a manager
+// use the key it already parsed, otherwise the PEM of the accessor is parsed.
 func GetParsedSigningKey(a JWTAccess) (interface{}, error) {
+	if p, ok := a.(parsedKeyAccess); ok {
+		if key := p.parsedSigningKey(); key != nil {
+			return key, nil
+		}
+	}
+
 	signingMethod := a.GetSigningMethod()
 	signingKey := a.GetSigningKey()
 
-	var key interface{}
-	if strings.HasPrefix(signingMethod.Alg(), "RS") {
-		privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(signingKey)
-		if err != nil {
-			return nil, fmt.Errorf("error parsing RSA key: %w", err)
-		}
-		key = privateKey
-	} else if strings.HasPrefix(signingMethod.Alg(), "HS") {
-		key = signingKey
+	if strings.HasPrefix(signingMethod.Alg(), "HS") {
+		return signingKey, nil
 	}
 
-	return key, nil
+	privateKey, format, err := keyutil.ParsePrivateKey(signingKey, nil)
+	if err != nil {
+		return nil, err
+	}
+	var ok bool
+	if strings.HasPrefix(signingMethod.Alg(), "RS") || strings.HasPrefix(signingMethod.Alg(), "PS") {
+		_, ok = privateKey.(*rsa.PrivateKey)
+	} else if strings.HasPrefix(signingMethod.Alg(), "ES") {
+		_, ok = privateKey.(*ecdsa.PrivateKey)
+	} else if signingMethod.Alg() == jwt.SigningMethodEdDSA.Alg() {
+		_, ok = privateKey.(ed25519.PrivateKey)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%s key can not be used with %s", format, signingMethod.Alg())
+	}
+	return privateKey, nil
 }
 
 func GenerateTokenString(a JWTAccess, accessClaims jwt.Claims, signingKey interface{}) (string, error) {
@@ -119,6 +147,13 @@ func IsExpiredError(err error) bool {
 	return true
 }
 
+// Reports whether expiry is the only reason the token is invalid. IsExpiredError is also
+// true for expired tokens whose signature is invalid.
+func isOnlyExpired(err error) bool {
+	vErr, ok := err.(*jwt.ValidationError)
+	return ok && vErr.Errors == jwt.ValidationErrorExpired
+}
+
 func GetClaimsWithUpdatedExpiry(token *jwt.Token, opt *options.AuthOptions) (jwt.MapClaims, error) {
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
@@ -133,5 +168,106 @@ func GetClaimsWithUpdatedExpiry(token *jwt.Token, opt *options.AuthOptions) (jwt
 	newExp := newIat + opt.Validity.AccessExpiresIn
 	claims["iat"] = newIat
 	claims["exp"] = newExp
+	if _, ok := claims["jti"]; ok {
+		claims["jti"] = uuid.NewString()
+	}
 	return claims, nil
 }
+
+// Returns the key that verifies the tokens signed by the accessor
+func GetVerificationKey(a JWTAccess) (interface{}, error) {
+	if s, ok := a.(*SignerAccess); ok {
+		return s.PublicKey, nil
+	}
+
+	key, err := GetParsedSigningKey(a)
+	if err != nil {
+		return nil, err
+	}
+	switch privateKey := key.(type) {
+	case *rsa.PrivateKey:
+		return &privateKey.PublicKey, nil
+	case *ecdsa.PrivateKey:
+		return &privateKey.PublicKey, nil
+	case ed25519.PrivateKey:
+		return privateKey.Public(), nil
+	}
+	return key, nil
+}
+
+// Issues a new access token (and id token if its claims are set and IsIdTokenAllowed allows it) for the refresh token.
+// The previous access token is verified with verifyKey and may already be expired.
+// Errors caused by the refresh token itself wrap ErrInvalidRefreshToken.
+func RenewToken(ctx context.Context, a JWTAccess, refreshToken string, verifyKey interface{}, opt *options.AuthOptions) (*Token, error) {
+	alg := a.GetSigningMethod().Alg()
+	return RenewTokenWithKeyfunc(ctx, a, refreshToken, func(token *jwt.Token) (interface{}, error) {
+		if token.Method.Alg() != alg {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return verifyKey, nil
+	}, opt)
+}
+
+// Same as RenewToken but the key that verifies the previous access token is resolved by keyFunc,
+// so that tokens signed by a key that has been rotated out can still be renewed
+func RenewTokenWithKeyfunc(ctx context.Context, a JWTAccess, refreshToken string, keyFunc jwt.Keyfunc, opt *options.AuthOptions) (*Token, error) {
+	idBytes, err := base64.URLEncoding.DecodeString(refreshToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to decode token: %v", ErrInvalidRefreshToken, err)
+	}
+
+	tokenInfo, err := opt.Store.GetTokenInfo(ctx, string(idBytes))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to get token info: %v", ErrInvalidRefreshToken, err)
+	}
+
+	if tokenInfo.Expiry.Before(time.Now()) {
+		return nil, fmt.Errorf("%w: refresh token expired", ErrInvalidRefreshToken)
+	}
+
+	// Tokens signed by a removed key, with another algorithm or with a bad signature can not be renewed
+	token, err := jwt.Parse(tokenInfo.AccessToken, keyFunc)
+	if err != nil && !isOnlyExpired(err) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRefreshToken, err)
+	}
+
+	accessClaims, err := GetClaimsWithUpdatedExpiry(token, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := GetParsedSigningKey(a)
+	if err != nil {
+		return nil, err
+	}
+	accessToken, err := GenerateTokenString(a, accessClaims, key)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Token{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   opt.Validity.AccessExpiresIn,
+	}
+
+	username, _ := accessClaims["sub"].(string)
+	scope, _ := accessClaims["scope"].(string)
+	clientId, _ := accessClaims["client_id"].(string)
+
+	if opt.IsIdTokenAllowed(scope, clientId) && opt.IsIdTokenClaimsSet(username) {
+		idClaims := opt.GetIdTokenClaims(username)
+		idToken, err := GenerateTokenString(a, idClaims, key)
+		if err != nil {
+			return nil, err
+		}
+		t.IdToken = idToken
+	}
+
+	err = opt.Store.UpdateTokenInfo(ctx, string(idBytes), accessToken, t.IdToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
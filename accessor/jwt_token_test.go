@@ -0,0 +1,99 @@
+package accessor
+
+import (
+	"context"
+	"crypto/elliptic"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Ashik80/oauth2jwtgen/claims"
+	"github.com/Ashik80/oauth2jwtgen/manager"
+	"github.com/Ashik80/oauth2jwtgen/options"
+	"github.com/golang-jwt/jwt"
+)
+
+// Compares issuing tokens with the key parsed once by the manager against parsing the PEM for every token.
+// The key of RS256-file is read from a file, which was read again for every token before keys were cached.
+func BenchmarkNewToken(b *testing.B) {
+	rs := manager.NewRSKeyManager()
+	rsKid, err := rs.GenerateKey(2048, jwt.SigningMethodRS256, "")
+	if err != nil {
+		b.Fatal(err)
+	}
+	rsFile := manager.NewRSKeyManager()
+	rsFilePath := filepath.Join(b.TempDir(), "rs.pem")
+	rsFileKid, err := rsFile.GenerateKey(2048, jwt.SigningMethodRS256, rsFilePath)
+	if err != nil {
+		b.Fatal(err)
+	}
+	es := manager.NewESKeyManager()
+	esKid, err := es.GenerateKey(elliptic.P256(), "")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	rsAccess, err := NewRSAccess(rsKid, rs)
+	if err != nil {
+		b.Fatal(err)
+	}
+	rsFileAccess, err := NewRSAccess(rsFileKid, rsFile)
+	if err != nil {
+		b.Fatal(err)
+	}
+	esAccess, err := NewESAccess(esKid, es)
+	if err != nil {
+		b.Fatal(err)
+	}
+	// Without the parsed key GetParsedSigningKey parses the PEM of the accessor every time
+	rsUncached := *rsAccess
+	rsUncached.privateKey = nil
+	esUncached := *esAccess
+	esUncached.privateKey = nil
+
+	benchmarks := []struct {
+		name string
+		// Returns the accessor of one token
+		access func() (JWTAccess, error)
+	}{
+		{"RS256/cached", func() (JWTAccess, error) { return rsAccess, nil }},
+		{"RS256/accessor-per-token", func() (JWTAccess, error) { return NewRSAccess(rsKid, rs) }},
+		{"RS256/reparse", func() (JWTAccess, error) { return &rsUncached, nil }},
+		{"RS256-file/cached", func() (JWTAccess, error) { return rsFileAccess, nil }},
+		{"RS256-file/accessor-per-token", func() (JWTAccess, error) { return NewRSAccess(rsFileKid, rsFile) }},
+		{"RS256-file/read-per-token", func() (JWTAccess, error) {
+			// Reads and parses the key file for every token without the cache of the manager
+			pem, err := os.ReadFile(rsFilePath)
+			if err != nil {
+				return nil, err
+			}
+			return &RSAccess{SignedKeyID: rsFileKid, SignedKey: pem, SigningMethod: jwt.SigningMethodRS256}, nil
+		}},
+		{"ES256/cached", func() (JWTAccess, error) { return esAccess, nil }},
+		{"ES256/accessor-per-token", func() (JWTAccess, error) { return NewESAccess(esKid, es) }},
+		{"ES256/reparse", func() (JWTAccess, error) { return &esUncached, nil }},
+	}
+
+	opt := options.DefaultAuthOptions()
+	// Refresh tokens need a store and do not depend on the key
+	opt.Validity.RefreshExpiresIn = 0
+	ctx := context.Background()
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				a, err := bm.access()
+				if err != nil {
+					b.Fatal(err)
+				}
+				c := &claims.JWTClaims{
+					AccessClaims: claims.GenerateAccessClaims("user", "issuer", "aud", "openid", nil, 600),
+				}
+				if _, err := NewToken(ctx, a, c, opt); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
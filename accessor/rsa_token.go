@@ -2,9 +2,7 @@ package accessor
 
 import (
 	"context"
-	"encoding/base64"
 	"fmt"
-	"time"
 
 	"github.com/Ashik80/oauth2jwtgen/manager"
 	"github.com/Ashik80/oauth2jwtgen/options"
@@ -13,101 +11,71 @@ import (
 	"github.com/golang-jwt/jwt"
 )
 
-type RS256Access struct {
+type RSAccess struct {
 	SignedKeyID   string
 	SignedKey     []byte
 	SigningMethod jwt.SigningMethod
+	privateKey    interface{}
 }
 
-func NewRS256Access(kid string, manager *manager.RSKeyManager) (*RS256Access, error) {
+// Deprecated: use RSAccess, which signs with the RS or PS method of its key
+type RS256Access = RSAccess
+
+// Returns an accessor that signs with the signing method recorded for the key in the manager
+func NewRSAccess(kid string, manager *manager.RSKeyManager) (*RSAccess, error) {
 	key, err := manager.GetKey(kid)
 	if err != nil {
 		return nil, err
 	}
-	r := &RS256Access{
+	method, err := manager.GetSigningMethod(kid)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, err := manager.GetPrivateKey(kid)
+	if err != nil {
+		return nil, err
+	}
+	r := &RSAccess{
 		SignedKeyID:   kid,
 		SignedKey:     key,
-		SigningMethod: jwt.SigningMethodRS256,
+		SigningMethod: method,
+		privateKey:    privateKey,
 	}
 
 	return r, nil
 }
 
-func (r *RS256Access) GetSigningKeyID() string {
+func NewRS256Access(kid string, manager *manager.RSKeyManager) (*RSAccess, error) {
+	r, err := NewRSAccess(kid, manager)
+	if err != nil {
+		return nil, err
+	}
+	if r.SigningMethod != jwt.SigningMethodRS256 {
+		return nil, fmt.Errorf("key %s can not be used with RS256", kid)
+	}
+	return r, nil
+}
+
+func (r *RSAccess) GetSigningKeyID() string {
 	return r.SignedKeyID
 }
 
-func (r *RS256Access) GetSigningKey() []byte {
+func (r *RSAccess) GetSigningKey() []byte {
 	return r.SignedKey
 }
 
-func (r *RS256Access) GetSigningMethod() jwt.SigningMethod {
+func (r *RSAccess) GetSigningMethod() jwt.SigningMethod {
 	return r.SigningMethod
 }
 
-func (r *RS256Access) RenewToken(ctx context.Context, refreshToken string, signingKey string, opt *options.AuthOptions) (*Token, error) {
-	idBytes, err := base64.URLEncoding.DecodeString(refreshToken)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decode token: %w", err)
-	}
-
-	tokenInfo, err := opt.Store.GetTokenInfo(ctx, string(idBytes))
-	if err != nil {
-		return nil, fmt.Errorf("failed to get token info: %w", err)
-	}
-
-	if tokenInfo.Expiry.Before(time.Now()) {
-		return nil, fmt.Errorf("refresh token expired")
-	}
-
-	prevAccessToken := tokenInfo.AccessToken
+func (r *RSAccess) parsedSigningKey() interface{} {
+	return r.privateKey
+}
 
+func (r *RSAccess) RenewToken(ctx context.Context, refreshToken string, signingKey string, opt *options.AuthOptions) (*Token, error) {
 	publicKey, err := verifier.LoadRSAPublicKeyFromFile(signingKey)
 	if err != nil {
 		return nil, err
 	}
-	token, err := verifier.ParseRSToken(prevAccessToken, publicKey)
-	if err != nil {
-		if !IsExpiredError(err) {
-			return nil, err
-		}
-	}
-
-	accessClaims, err := GetClaimsWithUpdatedExpiry(token, opt)
-	if err != nil {
-		return nil, err
-	}
-
-	key, err := GetParsedSigningKey(r)
-	if err != nil {
-		return nil, err
-	}
-	accessToken, err := GenerateTokenString(r, accessClaims, key)
-	if err != nil {
-		return nil, err
-	}
-
-	t := &Token{
-		AccessToken: accessToken,
-		TokenType:   "Bearer",
-		ExpiresIn:   accessClaims["exp"].(int64),
-	}
-
-	username := accessClaims["sub"].(string)
-
-	if opt.IsIdTokenClaimsSet(username) {
-		idClaims := opt.GetIdTokenClaims(username)
-		idToken, err := GenerateTokenString(r, idClaims, []byte(signingKey))
-		if err != nil {
-			return nil, err
-		}
-		t.IdToken = idToken
-	}
-
-	err = opt.Store.UpdateTokenInfo(ctx, string(idBytes), accessToken, t.IdToken)
-	if err != nil {
-		return nil, err
-	}
-
-	return t, nil
+	return RenewToken(ctx, r, refreshToken, publicKey, opt)
 }
@@ -0,0 +1,146 @@
+package accessor
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/Ashik80/oauth2jwtgen/manager"
+	"github.com/Ashik80/oauth2jwtgen/options"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// Signs tokens with a crypto.Signer instead of a private key. The private key never
+// has to be loaded so the signer can be backed by a KMS or an HSM.
+type SignerAccess struct {
+	SigningKeyID  string
+	Signer        crypto.Signer
+	PublicKey     crypto.PublicKey
+	SigningMethod jwt.SigningMethod
+}
+
+func NewSignerAccess(kid string, manager *manager.SignerKeyManager) (*SignerAccess, error) {
+	signer, err := manager.GetSigner(kid)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, err := manager.GetPublicKey(kid)
+	if err != nil {
+		return nil, err
+	}
+	method, err := manager.GetSigningMethod(kid)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &SignerAccess{
+		SigningKeyID:  kid,
+		Signer:        signer,
+		PublicKey:     publicKey,
+		SigningMethod: &SignerSigningMethod{SigningMethod: method},
+	}
+
+	return s, nil
+}
+
+func (s *SignerAccess) GetSigningKeyID() string {
+	return s.SigningKeyID
+}
+
+// Signer keys have no private key material so it returns nil
+func (s *SignerAccess) GetSigningKey() []byte {
+	return nil
+}
+
+func (s *SignerAccess) GetSigningMethod() jwt.SigningMethod {
+	return s.SigningMethod
+}
+
+func (s *SignerAccess) parsedSigningKey() interface{} {
+	return s.Signer
+}
+
+// The signingKey argument is not used, the previous access token is verified with the public key of the signer
+func (s *SignerAccess) RenewToken(ctx context.Context, refreshToken string, signingKey string, opt *options.AuthOptions) (*Token, error) {
+	return RenewToken(ctx, s, refreshToken, s.PublicKey, opt)
+}
+
+// Wraps a signing method so that tokens are signed with a crypto.Signer. The alg and the
+// verification are the ones of the wrapped method.
+type SignerSigningMethod struct {
+	jwt.SigningMethod
+}
+
+func (m *SignerSigningMethod) Sign(signingString string, key interface{}) (string, error) {
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return "", jwt.ErrInvalidKeyType
+	}
+
+	alg := m.Alg()
+	message := []byte(signingString)
+	var opts crypto.SignerOpts = crypto.Hash(0)
+
+	// EdDSA signs the message itself, the other algorithms sign its digest
+	if alg != jwt.SigningMethodEdDSA.Alg() {
+		hash, err := hashForAlg(alg)
+		if err != nil {
+			return "", err
+		}
+		hasher := hash.New()
+		hasher.Write(message)
+		message = hasher.Sum(nil)
+		opts = hash
+		if strings.HasPrefix(alg, "PS") {
+			opts = &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: hash}
+		}
+	}
+
+	sig, err := signer.Sign(rand.Reader, message, opts)
+	if err != nil {
+		return "", fmt.Errorf("signer failed: %w", err)
+	}
+
+	// Signers return ASN.1 encoded ECDSA signatures but JWS uses the fixed size r || s form
+	if publicKey, ok := signer.Public().(*ecdsa.PublicKey); ok {
+		sig, err = ecdsaSignatureToJWS(sig, publicKey)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return jwt.EncodeSegment(sig), nil
+}
+
+func hashForAlg(alg string) (crypto.Hash, error) {
+	switch {
+	case strings.HasSuffix(alg, "256"):
+		return crypto.SHA256, nil
+	case strings.HasSuffix(alg, "384"):
+		return crypto.SHA384, nil
+	case strings.HasSuffix(alg, "512"):
+		return crypto.SHA512, nil
+	}
+	return 0, fmt.Errorf("unsupported signing method %s", alg)
+}
+
+func ecdsaSignatureToJWS(der []byte, publicKey *ecdsa.PublicKey) ([]byte, error) {
+	var sig struct {
+		R, S *big.Int
+	}
+	if _, err := asn1.Unmarshal(der, &sig); err != nil {
+		return nil, fmt.Errorf("failed to decode ECDSA signature: %w", err)
+	}
+	size := (publicKey.Curve.Params().BitSize + 7) / 8
+	out := make([]byte, 2*size)
+	sig.R.FillBytes(out[:size])
+	sig.S.FillBytes(out[size:])
+	return out, nil
+}
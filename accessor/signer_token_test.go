@@ -0,0 +1,125 @@
+package accessor
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/asn1"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/Ashik80/oauth2jwtgen/manager"
+	"github.com/golang-jwt/jwt"
+)
+
+func TestSignerAccessSignsVerifiableTokens(t *testing.T) {
+	p256, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	p384, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	p521, _ := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	tests := []struct {
+		name   string
+		signer crypto.Signer
+		method jwt.SigningMethod
+		// Length of the JWS signature, zero when it depends on the key only
+		sigLen int
+	}{
+		{"ES256", p256, jwt.SigningMethodES256, 64},
+		{"ES384", p384, jwt.SigningMethodES384, 96},
+		{"ES512", p521, jwt.SigningMethodES512, 132},
+		{"RS256", rsaKey, jwt.SigningMethodRS256, 256},
+		{"PS256", rsaKey, jwt.SigningMethodPS256, 256},
+		{"EdDSA", edKey, jwt.SigningMethodEdDSA, ed25519.SignatureSize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := manager.NewSignerKeyManager()
+			if err := m.AddSigner("kid", tt.signer, nil, tt.method); err != nil {
+				t.Fatalf("AddSigner: %v", err)
+			}
+			a, err := NewSignerAccess("kid", m)
+			if err != nil {
+				t.Fatalf("NewSignerAccess: %v", err)
+			}
+
+			key, err := GetParsedSigningKey(a)
+			if err != nil {
+				t.Fatalf("GetParsedSigningKey: %v", err)
+			}
+			tokenString, err := GenerateTokenString(a, jwt.MapClaims{"sub": "user"}, key)
+			if err != nil {
+				t.Fatalf("GenerateTokenString: %v", err)
+			}
+
+			sig, err := jwt.DecodeSegment(tokenString[strings.LastIndex(tokenString, ".")+1:])
+			if err != nil {
+				t.Fatalf("decoding signature: %v", err)
+			}
+			if len(sig) != tt.sigLen {
+				t.Errorf("signature has %d bytes, want %d", len(sig), tt.sigLen)
+			}
+
+			token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+				return tt.signer.Public(), nil
+			})
+			if err != nil {
+				t.Fatalf("verifying token: %v", err)
+			}
+			if alg := token.Method.Alg(); alg != tt.method.Alg() {
+				t.Errorf("alg = %s, want %s", alg, tt.method.Alg())
+			}
+			if kid := token.Header["kid"]; kid != "kid" {
+				t.Errorf("kid = %v, want kid", kid)
+			}
+		})
+	}
+}
+
+func TestECDSASignatureToJWSPadsIntegers(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	der, err := asn1.Marshal(struct{ R, S *big.Int }{big.NewInt(1), big.NewInt(0x0203)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sig, err := ecdsaSignatureToJWS(der, &key.PublicKey)
+	if err != nil {
+		t.Fatalf("ecdsaSignatureToJWS: %v", err)
+	}
+	want := make([]byte, 64)
+	want[31] = 0x01
+	want[62], want[63] = 0x02, 0x03
+	if !bytes.Equal(sig, want) {
+		t.Errorf("signature = %x, want %x", sig, want)
+	}
+
+	if _, err := ecdsaSignatureToJWS([]byte("not asn.1"), &key.PublicKey); err == nil {
+		t.Error("expected an error for a malformed signature")
+	}
+}
+
+func TestAddSignerRejectsMissingSignerOrMethod(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	m := manager.NewSignerKeyManager()
+
+	if err := m.AddSigner("kid", key, nil, nil); err == nil {
+		t.Error("expected an error for a nil signing method")
+	}
+	if err := m.AddSigner("kid", nil, &key.PublicKey, jwt.SigningMethodES256); err == nil {
+		t.Error("expected an error for a nil signer")
+	}
+	if err := m.AddSigner("kid", key, nil, jwt.SigningMethodES384); err == nil {
+		t.Error("expected an error for a method that does not fit the key")
+	}
+	if ids := m.KeyIDs(); len(ids) != 0 {
+		t.Errorf("KeyIDs = %v, want none", ids)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
 )
 
 type JWTClaims struct {
@@ -13,8 +14,9 @@ type JWTClaims struct {
 
 type JWTAccessClaims struct {
 	jwt.StandardClaims
-	Scope string   `json:"scope,omitempty"`
-	Roles []string `json:"roles,omitempty"`
+	ClientId string   `json:"client_id,omitempty"`
+	Scope    string   `json:"scope,omitempty"`
+	Roles    []string `json:"roles,omitempty"`
 }
 
 type JWTIdClaims struct {
@@ -23,11 +25,13 @@ type JWTIdClaims struct {
 	GivenName         string   `json:"given_name,omitempty"`
 	FamilyName        string   `json:"family_name,omitempty"`
 	Email             string   `json:"email,omitempty"`
+	EmailVerified     *bool    `json:"email_verified,omitempty"`
 	Picture           string   `json:"picture,omitempty"`
 	Locale            string   `json:"locale,omitempty"`
 	PreferredUsername string   `json:"preferred_username,omitempty"`
 	Scope             string   `json:"scope,omitempty"`
 	Roles             []string `json:"roles,omitempty"`
+	Nonce             string   `json:"nonce,omitempty"`
 }
 
 func GenerateAccessClaims(sub string, issuer string, aud string, scope string, roles []string, expiresAfterSeconds int64) *JWTAccessClaims {
@@ -35,6 +39,7 @@ func GenerateAccessClaims(sub string, issuer string, aud string, scope string, r
 
 	claims := &JWTAccessClaims{
 		StandardClaims: jwt.StandardClaims{
+			Id:        uuid.NewString(),
 			Issuer:    issuer,
 			Audience:  aud,
 			Subject:   sub,
@@ -2,12 +2,10 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
 
-	"github.com/Ashik80/oauth2jwtgen/accessor"
 	"github.com/Ashik80/oauth2jwtgen/manager"
 	"github.com/Ashik80/oauth2jwtgen/options"
 	"github.com/Ashik80/oauth2jwtgen/server"
@@ -62,28 +60,20 @@ func main() {
 
 	mux := http.NewServeMux()
 
-	// Password grant flow endpoint example
+	// Token endpoint example with the password and refresh token grants.
+	// The refresh token is read from the form body or the refresh token cookie.
 	mux.HandleFunc(
 		"POST /oauth2/token",
-		oauthServer.ResourceOwnerPasswordCredential(
-			func(r *http.Request, opt *options.AuthOptions) *server.CallbackError {
-				username := r.FormValue("username")
-				password := r.FormValue("password")
-				fmt.Printf("do something with %s and %s\n", username, password)
-				return nil
-			}))
-
-	// Renew token flow endpoint example
-	mux.HandleFunc(
-		"GET /oauth2/refresh-token",
-		func(w http.ResponseWriter, r *http.Request) {
-			refreshCookie, _ := r.Cookie("refresh_token")
-			acc, _ := accessor.NewHS256Access("key1", m)
-			token, _ := acc.RenewToken(r.Context(), refreshCookie.Value, secretKey, o)
-			w.Header().Add("Content-Type", "application/json")
-			w.WriteHeader(http.StatusOK)
-			json.NewEncoder(w).Encode(token)
-		})
+		oauthServer.Token(map[string]http.HandlerFunc{
+			server.GrantTypePassword: oauthServer.ResourceOwnerPasswordCredential(
+				func(r *http.Request, opt *options.AuthOptions) *server.CallbackError {
+					username := r.FormValue("username")
+					password := r.FormValue("password")
+					fmt.Printf("do something with %s and %s\n", username, password)
+					return nil
+				}),
+			server.GrantTypeRefreshToken: oauthServer.RefreshToken(),
+		}))
 
 	http.ListenAndServe(":4040", enableCors(mux))
 }
@@ -0,0 +1,204 @@
+package jwk
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+)
+
+// JSON Web Key as described in RFC 7517. The private key parameters are only set
+// when a private key is read from a JWK, keys built by FromPublicKey never have them.
+type Key struct {
+	Kty string `json:"kty"`
+	Use string `json:"use,omitempty"`
+	Kid string `json:"kid,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	D   string `json:"d,omitempty"`
+	P   string `json:"p,omitempty"`
+	Q   string `json:"q,omitempty"`
+}
+
+type Set struct {
+	Keys []Key `json:"keys"`
+}
+
+func (s *Set) Key(kid string) (*Key, bool) {
+	for i := range s.Keys {
+		if s.Keys[i].Kid == kid {
+			return &s.Keys[i], true
+		}
+	}
+	return nil, false
+}
+
+// Returns the signing key entry for a public key
+func FromPublicKey(kid string, alg string, publicKey crypto.PublicKey) (*Key, error) {
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		return &Key{
+			Kty: "RSA",
+			Use: "sig",
+			Kid: kid,
+			Alg: alg,
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}, nil
+	case *ecdsa.PublicKey:
+		// Coordinates are padded to the size of the curve as RFC 7518 section 6.2.1 requires
+		size := (key.Curve.Params().BitSize + 7) / 8
+		return &Key{
+			Kty: "EC",
+			Use: "sig",
+			Kid: kid,
+			Alg: alg,
+			Crv: key.Curve.Params().Name,
+			X:   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, size))),
+			Y:   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, size))),
+		}, nil
+	case ed25519.PublicKey:
+		return &Key{
+			Kty: "OKP",
+			Use: "sig",
+			Kid: kid,
+			Alg: alg,
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(key),
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported public key type %T", publicKey)
+}
+
+func (k *Key) PublicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode modulus: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode exponent: %w", err)
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode x coordinate: %w", err)
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode y coordinate: %w", err)
+		}
+		publicKey := &ecdsa.PublicKey{
+			Curve: curve,
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}
+		if _, err := publicKey.ECDH(); err != nil {
+			return nil, fmt.Errorf("invalid %s public key: %w", k.Crv, err)
+		}
+		return publicKey, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode public key: %w", err)
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 public key size %d", len(x))
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %s", k.Kty)
+}
+
+// Returns the private key of a JWK that has the private key parameters
+func (k *Key) PrivateKey() (crypto.PrivateKey, error) {
+	if k.D == "" {
+		return nil, fmt.Errorf("%s key has no private key parameters", k.Kty)
+	}
+	d, err := base64.RawURLEncoding.DecodeString(k.D)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode private exponent: %w", err)
+	}
+
+	publicKey, err := k.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	switch publicKey := publicKey.(type) {
+	case *rsa.PublicKey:
+		if k.P == "" || k.Q == "" {
+			return nil, fmt.Errorf("RSA key has no prime factors")
+		}
+		p, err := base64.RawURLEncoding.DecodeString(k.P)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode first prime factor: %w", err)
+		}
+		q, err := base64.RawURLEncoding.DecodeString(k.Q)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode second prime factor: %w", err)
+		}
+		privateKey := &rsa.PrivateKey{
+			PublicKey: *publicKey,
+			D:         new(big.Int).SetBytes(d),
+			Primes:    []*big.Int{new(big.Int).SetBytes(p), new(big.Int).SetBytes(q)},
+		}
+		if err := privateKey.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid RSA private key: %w", err)
+		}
+		privateKey.Precompute()
+		return privateKey, nil
+	case *ecdsa.PublicKey:
+		privateKey := &ecdsa.PrivateKey{
+			PublicKey: *publicKey,
+			D:         new(big.Int).SetBytes(d),
+		}
+		ecdhKey, err := privateKey.ECDH()
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s private key: %w", k.Crv, err)
+		}
+		expected, _ := publicKey.ECDH()
+		if !ecdhKey.PublicKey().Equal(expected) {
+			return nil, fmt.Errorf("%s private key does not match the public key", k.Crv)
+		}
+		return privateKey, nil
+	case ed25519.PublicKey:
+		if len(d) != ed25519.SeedSize {
+			return nil, fmt.Errorf("invalid Ed25519 private key size %d", len(d))
+		}
+		privateKey := ed25519.NewKeyFromSeed(d)
+		if !publicKey.Equal(privateKey.Public()) {
+			return nil, fmt.Errorf("Ed25519 private key does not match the public key")
+		}
+		return privateKey, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %s", k.Kty)
+}
@@ -0,0 +1,236 @@
+package keyutil
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Ashik80/oauth2jwtgen/jwk"
+)
+
+// Encoding a key was read from
+type Format string
+
+const (
+	FormatPKCS1       Format = "PKCS#1"
+	FormatPKCS8       Format = "PKCS#8"
+	FormatSEC1        Format = "SEC1"
+	FormatPKIX        Format = "PKIX"
+	FormatCertificate Format = "certificate"
+	FormatJWK         Format = "JWK"
+)
+
+// Returned when an encrypted private key is read without a passphrase
+var ErrPassphraseRequired = errors.New("passphrase required")
+
+// Returned when the passphrase does not decrypt the private key
+var ErrIncorrectPassphrase = errors.New("incorrect passphrase")
+
+// Returned for PEM blocks encrypted with the legacy scheme of RFC 1423 (a Proc-Type: 4,ENCRYPTED
+// header), which is insecure. Convert them to encrypted PKCS#8 with
+// openssl pkcs8 -topk8 -v2 aes-256-cbc.
+var ErrLegacyEncryption = errors.New("legacy PEM encryption is insecure and not supported, convert the key to encrypted PKCS#8")
+
+func (f Format) encrypted() Format {
+	return "encrypted " + f
+}
+
+// Parses a private key from a PKCS#1, PKCS#8 or SEC1 PEM block or from a JWK. A leading
+// EC PARAMETERS block is skipped. Encrypted PKCS#8 keys are decrypted with the passphrase,
+// see decryptPKCS8 for the supported algorithms. The detected format is returned with the
+// key and named in the error.
+func ParsePrivateKey(data []byte, passphrase []byte) (crypto.PrivateKey, Format, error) {
+	if isJSON(data) {
+		k, err := parseJWK(data)
+		if err != nil {
+			return nil, FormatJWK, err
+		}
+		privateKey, err := k.PrivateKey()
+		if err != nil {
+			return nil, FormatJWK, fmt.Errorf("error parsing %s private key: %w", FormatJWK, err)
+		}
+		return privateKey, FormatJWK, nil
+	}
+
+	block := decodeKeyBlock(data)
+	if block == nil {
+		return nil, "", fmt.Errorf("no PEM block or JWK found")
+	}
+
+	var format Format
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		format = FormatPKCS1
+	case "EC PRIVATE KEY":
+		format = FormatSEC1
+	case "PRIVATE KEY":
+		format = FormatPKCS8
+	case "ENCRYPTED PRIVATE KEY":
+		format = FormatPKCS8.encrypted()
+		der, err := decryptPKCS8(block.Bytes, passphrase)
+		if err != nil {
+			return nil, format, fmt.Errorf("error decrypting %s private key: %w", format, err)
+		}
+		privateKey, err := x509.ParsePKCS8PrivateKey(der)
+		if err != nil {
+			return nil, format, fmt.Errorf("error parsing %s private key: %w", format, err)
+		}
+		return privateKey, format, nil
+	case "PUBLIC KEY", "RSA PUBLIC KEY", "CERTIFICATE":
+		return nil, "", fmt.Errorf("%s PEM block does not contain a private key", block.Type)
+	default:
+		return nil, "", fmt.Errorf("unsupported PEM block type %s", block.Type)
+	}
+
+	if isLegacyEncrypted(block) {
+		format = format.encrypted()
+		return nil, format, fmt.Errorf("error decrypting %s private key: %w", format, ErrLegacyEncryption)
+	}
+
+	var privateKey crypto.PrivateKey
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		privateKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		privateKey, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		privateKey, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, format, fmt.Errorf("error parsing %s private key: %w", format, err)
+	}
+	return privateKey, format, nil
+}
+
+// Parses a public key from a PKIX or PKCS#1 PEM block, from the certificate of a
+// CERTIFICATE block or from a JWK. The public half of an unencrypted private key is
+// returned as well. The detected format is returned with the key and named in the error.
+func ParsePublicKey(data []byte) (crypto.PublicKey, Format, error) {
+	if isJSON(data) {
+		k, err := parseJWK(data)
+		if err != nil {
+			return nil, FormatJWK, err
+		}
+		publicKey, err := k.PublicKey()
+		if err != nil {
+			return nil, FormatJWK, fmt.Errorf("error parsing %s public key: %w", FormatJWK, err)
+		}
+		return publicKey, FormatJWK, nil
+	}
+
+	block := decodeKeyBlock(data)
+	if block == nil {
+		return nil, "", fmt.Errorf("no PEM block or JWK found")
+	}
+
+	switch block.Type {
+	case "PUBLIC KEY":
+		publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, FormatPKIX, fmt.Errorf("error parsing %s public key: %w", FormatPKIX, err)
+		}
+		return publicKey, FormatPKIX, nil
+	case "RSA PUBLIC KEY":
+		publicKey, err := x509.ParsePKCS1PublicKey(block.Bytes)
+		if err != nil {
+			return nil, FormatPKCS1, fmt.Errorf("error parsing %s public key: %w", FormatPKCS1, err)
+		}
+		return publicKey, FormatPKCS1, nil
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, FormatCertificate, fmt.Errorf("error parsing %s: %w", FormatCertificate, err)
+		}
+		return cert.PublicKey, FormatCertificate, nil
+	}
+
+	privateKey, format, err := ParsePrivateKey(data, nil)
+	if err != nil {
+		return nil, format, err
+	}
+	signer, ok := privateKey.(crypto.Signer)
+	if !ok {
+		return nil, format, fmt.Errorf("unsupported %s private key type %T", format, privateKey)
+	}
+	return signer.Public(), format, nil
+}
+
+// Reports whether data is a PEM private key that is encrypted, so that it can only be parsed
+// with a passphrase
+func IsEncrypted(data []byte) bool {
+	block := decodeKeyBlock(data)
+	return block != nil && (block.Type == "ENCRYPTED PRIVATE KEY" || isLegacyEncrypted(block))
+}
+
+// Reads and parses a private key file, see ParsePrivateKey
+func LoadPrivateKeyFromFile(filePath string, passphrase []byte) (crypto.PrivateKey, Format, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read file: %w", err)
+	}
+	return ParsePrivateKey(data, passphrase)
+}
+
+// Reads and parses a public key file, see ParsePublicKey
+func LoadPublicKeyFromFile(filePath string) (crypto.PublicKey, Format, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read file: %w", err)
+	}
+	return ParsePublicKey(data)
+}
+
+// Returns the first PEM block that is not an EC PARAMETERS block, which openssl ecparam -genkey
+// writes before the key
+func decodeKeyBlock(data []byte) *pem.Block {
+	for {
+		block, rest := pem.Decode(data)
+		if block == nil || block.Type != "EC PARAMETERS" {
+			return block
+		}
+		data = rest
+	}
+}
+
+// Reports whether the block has the headers of RFC 1423 encryption
+func isLegacyEncrypted(block *pem.Block) bool {
+	_, hasDEKInfo := block.Headers["DEK-Info"]
+	return hasDEKInfo || strings.Contains(block.Headers["Proc-Type"], "ENCRYPTED")
+}
+
+func isJSON(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) > 0 && data[0] == '{'
+}
+
+// Parses a JWK, or a JWK set that holds a single key
+func parseJWK(data []byte) (*jwk.Key, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", FormatJWK, err)
+	}
+
+	if _, isSet := fields["keys"]; isSet {
+		var set jwk.Set
+		if err := json.Unmarshal(data, &set); err != nil {
+			return nil, fmt.Errorf("error parsing %s set: %w", FormatJWK, err)
+		}
+		if len(set.Keys) != 1 {
+			return nil, fmt.Errorf("%s set has %d keys, expected one", FormatJWK, len(set.Keys))
+		}
+		return &set.Keys[0], nil
+	}
+
+	var k jwk.Key
+	if err := json.Unmarshal(data, &k); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", FormatJWK, err)
+	}
+	return &k, nil
+}
@@ -0,0 +1,50 @@
+package keyutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"testing"
+)
+
+// ec-params.pem was written by openssl ecparam -name prime256v1 -genkey, which puts an
+// EC PARAMETERS block before the key
+func TestParsePrivateKeySkipsECParameters(t *testing.T) {
+	key, format, err := ParsePrivateKey(readTestdata(t, "ec-params.pem"), nil)
+	if err != nil {
+		t.Fatalf("ParsePrivateKey: %v", err)
+	}
+	if format != FormatSEC1 {
+		t.Errorf("format = %q, want %q", format, FormatSEC1)
+	}
+	ecKey, ok := key.(*ecdsa.PrivateKey)
+	if !ok || ecKey.Curve != elliptic.P256() {
+		t.Errorf("key = %T, want a P-256 *ecdsa.PrivateKey", key)
+	}
+}
+
+func TestParsePublicKeySkipsECParameters(t *testing.T) {
+	privateKey, _, err := ParsePrivateKey(readTestdata(t, "ec-params.pem"), nil)
+	if err != nil {
+		t.Fatalf("ParsePrivateKey: %v", err)
+	}
+	publicKey, format, err := ParsePublicKey(readTestdata(t, "ec-params.pem"))
+	if err != nil {
+		t.Fatalf("ParsePublicKey: %v", err)
+	}
+	if format != FormatSEC1 {
+		t.Errorf("format = %q, want %q", format, FormatSEC1)
+	}
+	if !privateKey.(*ecdsa.PrivateKey).PublicKey.Equal(publicKey) {
+		t.Error("public key is not the public half of the private key")
+	}
+}
+
+func TestParseECParametersOnly(t *testing.T) {
+	data := []byte("-----BEGIN EC PARAMETERS-----\nBggqhkjOPQMBBw==\n-----END EC PARAMETERS-----\n")
+	if _, _, err := ParsePrivateKey(data, nil); err == nil {
+		t.Error("ParsePrivateKey succeeded without a key block")
+	}
+	if _, _, err := ParsePublicKey(data); err == nil {
+		t.Error("ParsePublicKey succeeded without a key block")
+	}
+}
@@ -0,0 +1,101 @@
+package keyutil
+
+import (
+	"crypto/rsa"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Test vectors of RFC 6070
+func TestPBKDF2(t *testing.T) {
+	tests := []struct {
+		password   string
+		salt       string
+		iterations int
+		keyLen     int
+		want       string
+	}{
+		{"password", "salt", 1, 20, "0c60c80f961f0e71f3a9b524af6012062fe037a6"},
+		{"password", "salt", 2, 20, "ea6c014dc72d6f8ccd1ed92ace1d41f0d8de8957"},
+		{"password", "salt", 4096, 20, "4b007901b765489abead49d926f721d065a429c1"},
+		{"password", "salt", 16777216, 20, "eefe3d61cd4da4e4e9945b3d6ba2158c2634e984"},
+		{"passwordPASSWORDpassword", "saltSALTsaltSALTsaltSALTsaltSALTsalt", 4096, 25, "3d2eec4fe41c849b80c8d83662c0e44a8b291a964cf2f07038"},
+		{"pass\x00word", "sa\x00lt", 4096, 16, "56fa6aa75548099dcc37d7f03425e0c3"},
+	}
+
+	for _, tt := range tests {
+		if tt.iterations > 100000 && testing.Short() {
+			continue
+		}
+		got := pbkdf2([]byte(tt.password), []byte(tt.salt), tt.iterations, tt.keyLen, sha1.New)
+		if hex.EncodeToString(got) != tt.want {
+			t.Errorf("pbkdf2(%q, %q, %d, %d) = %x, want %s", tt.password, tt.salt, tt.iterations, tt.keyLen, got, tt.want)
+		}
+	}
+}
+
+// The fixtures in testdata are rsa.pem encrypted by openssl with the passphrase "secret":
+//
+//	openssl pkcs8 -topk8 -in rsa.pem -v2 <cipher> -v2prf <prf> -passout pass:secret
+var encryptedPKCS8Fixtures = []string{
+	"rsa-aes-128-cbc-hmacWithSHA1.pem",
+	"rsa-aes-128-cbc-hmacWithSHA256.pem",
+	"rsa-aes-128-cbc-hmacWithSHA512.pem",
+	"rsa-aes-256-cbc-hmacWithSHA1.pem",
+	"rsa-aes-256-cbc-hmacWithSHA256.pem",
+	"rsa-aes-256-cbc-hmacWithSHA512.pem",
+	"rsa-des-ede3-cbc-hmacWithSHA1.pem",
+	"rsa-des-ede3-cbc-hmacWithSHA256.pem",
+	"rsa-des-ede3-cbc-hmacWithSHA512.pem",
+}
+
+func readTestdata(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	return data
+}
+
+func TestParsePrivateKeyEncryptedPKCS8(t *testing.T) {
+	want, _, err := ParsePrivateKey(readTestdata(t, "rsa.pem"), nil)
+	if err != nil {
+		t.Fatalf("ParsePrivateKey(rsa.pem): %v", err)
+	}
+
+	for _, name := range encryptedPKCS8Fixtures {
+		t.Run(name, func(t *testing.T) {
+			data := readTestdata(t, name)
+
+			key, format, err := ParsePrivateKey(data, []byte("secret"))
+			if err != nil {
+				t.Fatalf("ParsePrivateKey: %v", err)
+			}
+			if format != FormatPKCS8.encrypted() {
+				t.Errorf("format = %q, want %q", format, FormatPKCS8.encrypted())
+			}
+			if !key.(*rsa.PrivateKey).Equal(want) {
+				t.Error("decrypted key is not the key of rsa.pem")
+			}
+
+			if _, _, err := ParsePrivateKey(data, []byte("wrong passphrase")); !errors.Is(err, ErrIncorrectPassphrase) {
+				t.Errorf("wrong passphrase: err = %v, want %v", err, ErrIncorrectPassphrase)
+			}
+			if _, _, err := ParsePrivateKey(data, nil); !errors.Is(err, ErrPassphraseRequired) {
+				t.Errorf("missing passphrase: err = %v, want %v", err, ErrPassphraseRequired)
+			}
+		})
+	}
+}
+
+func TestParsePublicKeyEncryptedPKCS8(t *testing.T) {
+	data := readTestdata(t, encryptedPKCS8Fixtures[0])
+	if _, _, err := ParsePublicKey(data); !errors.Is(err, ErrPassphraseRequired) {
+		t.Errorf("err = %v, want %v", err, ErrPassphraseRequired)
+	}
+}
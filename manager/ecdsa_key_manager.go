@@ -0,0 +1,185 @@
+package manager
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"fmt"
+	"sync"
+
+	"github.com/Ashik80/oauth2jwtgen/keyutil"
+	"github.com/golang-jwt/jwt"
+)
+
+type ESKeyManager struct {
+	Keys  map[string]string
+	pems  map[string][]byte
+	cache keyCache
+	keyLifecycle
+	mu sync.Mutex
+}
+
+func NewESKeyManager() *ESKeyManager {
+	return &ESKeyManager{
+		Keys: make(map[string]string),
+	}
+}
+
+func (m *ESKeyManager) AddKey(kid, secret string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Keys[kid] = secret
+	delete(m.pems, kid)
+	m.cache.invalidate(kid)
+	m.keyLifecycle.add(kid)
+}
+
+// Adds a key from the source. A FileSource is added as a path, other sources
+// are loaded when the key is added and kept in memory.
+func (m *ESKeyManager) AddKeySource(kid string, source KeySource) error {
+	return addPEMSource(m, kid, source)
+}
+
+func (m *ESKeyManager) GetKey(kid string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if pem, exists := m.pems[kid]; exists {
+		return pem, nil
+	}
+	path, exists := m.Keys[kid]
+	if !exists {
+		return nil, fmt.Errorf("key does not exist")
+	}
+	return m.cache.read(kid, path)
+}
+
+// Returns the parsed private key. The key is parsed once and parsed again only when its file changes.
+func (m *ESKeyManager) GetPrivateKey(kid string) (*ecdsa.PrivateKey, error) {
+	m.mu.Lock()
+	pem, isPem := m.pems[kid]
+	path, isFile := m.Keys[kid]
+	m.mu.Unlock()
+	if !isPem && !isFile {
+		return nil, fmt.Errorf("key does not exist")
+	}
+
+	key, err := m.cache.privateKey(kid, pem, path, parseECDSAPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return key.(*ecdsa.PrivateKey), nil
+}
+
+// Sets the passphrase that decrypts the key when it is an encrypted PKCS#8 PEM
+func (m *ESKeyManager) SetPassphrase(kid string, passphrase []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.hasKey(kid) {
+		return fmt.Errorf("key does not exist")
+	}
+	m.cache.setPassphrase(kid, passphrase)
+	return nil
+}
+
+// Adds a PEM encoded private key that is kept in memory instead of being read from a file
+func (m *ESKeyManager) AddPEMKey(kid string, pem []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.pems == nil {
+		m.pems = make(map[string][]byte)
+	}
+	delete(m.Keys, kid)
+	m.pems[kid] = pem
+	m.cache.invalidate(kid)
+	m.keyLifecycle.add(kid)
+}
+
+func (m *ESKeyManager) hasKey(kid string) bool {
+	_, isFile := m.Keys[kid]
+	_, isPem := m.pems[kid]
+	return isFile || isPem
+}
+
+// Returns the signing method that matches the curve of the key
+func (m *ESKeyManager) GetSigningMethod(kid string) (jwt.SigningMethod, error) {
+	privateKey, err := m.GetPrivateKey(kid)
+	if err != nil {
+		return nil, err
+	}
+	return ESSigningMethod(privateKey.Curve)
+}
+
+func (m *ESKeyManager) GetSigningKey(kid string) (interface{}, error) {
+	privateKey, err := m.GetPrivateKey(kid)
+	if err != nil {
+		return nil, err
+	}
+	return privateKey, nil
+}
+
+func (m *ESKeyManager) GetKeyInfo(kid string) (KeyInfo, error) {
+	privateKey, err := m.GetPrivateKey(kid)
+	if err != nil {
+		return KeyInfo{}, err
+	}
+	method, err := m.GetSigningMethod(kid)
+	if err != nil {
+		return KeyInfo{}, err
+	}
+	return m.keyLifecycle.keyInfo(kid, KeyTypeEC, method, privateKey.Public())
+}
+
+func (m *ESKeyManager) RemoveKey(kid string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.Keys, kid)
+	delete(m.pems, kid)
+	m.cache.remove(kid)
+	m.keyLifecycle.remove(kid)
+}
+
+func (m *ESKeyManager) PublicKeys() ([]PublicKey, error) {
+	kids := m.KeyIDs()
+
+	keys := make([]PublicKey, 0, len(kids))
+	for _, kid := range kids {
+		privateKey, err := m.GetPrivateKey(kid)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", kid, err)
+		}
+		method, err := ESSigningMethod(privateKey.Curve)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, PublicKey{
+			Kid: kid,
+			Alg: method.Alg(),
+			Key: &privateKey.PublicKey,
+		})
+	}
+	return keys, nil
+}
+
+// Returns the ES256, ES384 or ES512 signing method that matches the curve of the key
+func ESSigningMethod(curve elliptic.Curve) (*jwt.SigningMethodECDSA, error) {
+	switch curve {
+	case elliptic.P256():
+		return jwt.SigningMethodES256, nil
+	case elliptic.P384():
+		return jwt.SigningMethodES384, nil
+	case elliptic.P521():
+		return jwt.SigningMethodES512, nil
+	}
+	return nil, fmt.Errorf("unsupported curve %s", curve.Params().Name)
+}
+
+func parseECDSAPrivateKey(pem []byte, passphrase []byte) (crypto.PrivateKey, error) {
+	privateKey, format, err := keyutil.ParsePrivateKey(pem, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := privateKey.(*ecdsa.PrivateKey); !ok {
+		return nil, fmt.Errorf("%s key is not an ECDSA key", format)
+	}
+	return privateKey, nil
+}
@@ -0,0 +1,167 @@
+package manager
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"fmt"
+	"sync"
+
+	"github.com/Ashik80/oauth2jwtgen/keyutil"
+	"github.com/golang-jwt/jwt"
+)
+
+type EdKeyManager struct {
+	Keys  map[string]string
+	pems  map[string][]byte
+	cache keyCache
+	keyLifecycle
+	mu sync.Mutex
+}
+
+func NewEdKeyManager() *EdKeyManager {
+	return &EdKeyManager{
+		Keys: make(map[string]string),
+	}
+}
+
+func (m *EdKeyManager) AddKey(kid, secret string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Keys[kid] = secret
+	delete(m.pems, kid)
+	m.cache.invalidate(kid)
+	m.keyLifecycle.add(kid)
+}
+
+// Adds a key from the source. A FileSource is added as a path, other sources
+// are loaded when the key is added and kept in memory.
+func (m *EdKeyManager) AddKeySource(kid string, source KeySource) error {
+	return addPEMSource(m, kid, source)
+}
+
+func (m *EdKeyManager) GetKey(kid string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if pem, exists := m.pems[kid]; exists {
+		return pem, nil
+	}
+	path, exists := m.Keys[kid]
+	if !exists {
+		return nil, fmt.Errorf("key does not exist")
+	}
+	return m.cache.read(kid, path)
+}
+
+// Returns the parsed private key. The key is parsed once and parsed again only when its file changes.
+func (m *EdKeyManager) GetPrivateKey(kid string) (ed25519.PrivateKey, error) {
+	m.mu.Lock()
+	pem, isPem := m.pems[kid]
+	path, isFile := m.Keys[kid]
+	m.mu.Unlock()
+	if !isPem && !isFile {
+		return nil, fmt.Errorf("key does not exist")
+	}
+
+	key, err := m.cache.privateKey(kid, pem, path, parseEd25519PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return key.(ed25519.PrivateKey), nil
+}
+
+// Sets the passphrase that decrypts the key when it is an encrypted PKCS#8 PEM
+func (m *EdKeyManager) SetPassphrase(kid string, passphrase []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.hasKey(kid) {
+		return fmt.Errorf("key does not exist")
+	}
+	m.cache.setPassphrase(kid, passphrase)
+	return nil
+}
+
+// Adds a PEM encoded private key that is kept in memory instead of being read from a file
+func (m *EdKeyManager) AddPEMKey(kid string, pem []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.pems == nil {
+		m.pems = make(map[string][]byte)
+	}
+	delete(m.Keys, kid)
+	m.pems[kid] = pem
+	m.cache.invalidate(kid)
+	m.keyLifecycle.add(kid)
+}
+
+func (m *EdKeyManager) hasKey(kid string) bool {
+	_, isFile := m.Keys[kid]
+	_, isPem := m.pems[kid]
+	return isFile || isPem
+}
+
+func (m *EdKeyManager) GetSigningMethod(kid string) (jwt.SigningMethod, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.hasKey(kid) {
+		return nil, fmt.Errorf("key does not exist")
+	}
+	return jwt.SigningMethodEdDSA, nil
+}
+
+func (m *EdKeyManager) GetSigningKey(kid string) (interface{}, error) {
+	privateKey, err := m.GetPrivateKey(kid)
+	if err != nil {
+		return nil, err
+	}
+	return privateKey, nil
+}
+
+func (m *EdKeyManager) GetKeyInfo(kid string) (KeyInfo, error) {
+	privateKey, err := m.GetPrivateKey(kid)
+	if err != nil {
+		return KeyInfo{}, err
+	}
+	method, err := m.GetSigningMethod(kid)
+	if err != nil {
+		return KeyInfo{}, err
+	}
+	return m.keyLifecycle.keyInfo(kid, KeyTypeOKP, method, privateKey.Public())
+}
+
+func (m *EdKeyManager) RemoveKey(kid string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.Keys, kid)
+	delete(m.pems, kid)
+	m.cache.remove(kid)
+	m.keyLifecycle.remove(kid)
+}
+
+func (m *EdKeyManager) PublicKeys() ([]PublicKey, error) {
+	kids := m.KeyIDs()
+
+	keys := make([]PublicKey, 0, len(kids))
+	for _, kid := range kids {
+		privateKey, err := m.GetPrivateKey(kid)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", kid, err)
+		}
+		keys = append(keys, PublicKey{
+			Kid: kid,
+			Alg: jwt.SigningMethodEdDSA.Alg(),
+			Key: privateKey.Public(),
+		})
+	}
+	return keys, nil
+}
+
+func parseEd25519PrivateKey(pem []byte, passphrase []byte) (crypto.PrivateKey, error) {
+	privateKey, format, err := keyutil.ParsePrivateKey(pem, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := privateKey.(ed25519.PrivateKey); !ok {
+		return nil, fmt.Errorf("%s key is not an Ed25519 key", format)
+	}
+	return privateKey, nil
+}
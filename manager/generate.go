@@ -0,0 +1,186 @@
+package manager
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"os"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+)
+
+// Returns a random key id for a generated key
+func NewKid() string {
+	return uuid.NewString()
+}
+
+func GenerateRSAKey(bits int) (*rsa.PrivateKey, error) {
+	if bits < 2048 {
+		return nil, fmt.Errorf("RSA keys must have at least 2048 bits")
+	}
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate RSA key: %w", err)
+	}
+	return key, nil
+}
+
+// Generates a key on the P-256, P-384 or P-521 curve
+func GenerateECDSAKey(curve elliptic.Curve) (*ecdsa.PrivateKey, error) {
+	if _, err := ESSigningMethod(curve); err != nil {
+		return nil, err
+	}
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate ECDSA key: %w", err)
+	}
+	return key, nil
+}
+
+func GenerateEd25519Key() (ed25519.PrivateKey, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate Ed25519 key: %w", err)
+	}
+	return key, nil
+}
+
+// Returns a base64url encoded secret of size random bytes. HMAC secrets should be at least
+// as long as the hash, so 32 bytes for HS256 and 64 bytes for HS512.
+func GenerateHMACSecret(size int) (string, error) {
+	if size < 32 {
+		return "", fmt.Errorf("HMAC secrets must have at least 32 bytes")
+	}
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate HMAC secret: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Encodes the private key as a PKCS#8 PEM block
+func EncodePrivateKeyPEM(key crypto.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal private key: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// Writes the private key as PKCS#8 PEM to a new file that only the owner can read.
+// Existing files are not overwritten.
+func WritePrivateKeyPEM(path string, key crypto.PrivateKey) error {
+	b, err := EncodePrivateKeyPEM(key)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create key file: %w", err)
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write key file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write key file: %w", err)
+	}
+	return nil
+}
+
+// Generates an RSA key of the given size that signs with method and adds it with a new kid.
+// The key is written to path when it is not empty and kept in memory otherwise.
+func (m *RSKeyManager) GenerateKey(bits int, method jwt.SigningMethod, path string) (string, error) {
+	if err := checkRSASigningMethod(method); err != nil {
+		return "", err
+	}
+	key, err := GenerateRSAKey(bits)
+	if err != nil {
+		return "", err
+	}
+	kid := NewKid()
+	if path != "" {
+		if err := WritePrivateKeyPEM(path, key); err != nil {
+			return "", err
+		}
+		m.addKey(kid, path, nil, method)
+		return kid, nil
+	}
+	b, err := EncodePrivateKeyPEM(key)
+	if err != nil {
+		return "", err
+	}
+	m.addKey(kid, "", b, method)
+	return kid, nil
+}
+
+// Generates an ECDSA key on the curve and adds it with a new kid.
+// The key is written to path when it is not empty and kept in memory otherwise.
+func (m *ESKeyManager) GenerateKey(curve elliptic.Curve, path string) (string, error) {
+	key, err := GenerateECDSAKey(curve)
+	if err != nil {
+		return "", err
+	}
+	kid := NewKid()
+	if err := addGeneratedKey(m, kid, key, path); err != nil {
+		return "", err
+	}
+	return kid, nil
+}
+
+// Generates an Ed25519 key and adds it with a new kid.
+// The key is written to path when it is not empty and kept in memory otherwise.
+func (m *EdKeyManager) GenerateKey(path string) (string, error) {
+	key, err := GenerateEd25519Key()
+	if err != nil {
+		return "", err
+	}
+	kid := NewKid()
+	if err := addGeneratedKey(m, kid, key, path); err != nil {
+		return "", err
+	}
+	return kid, nil
+}
+
+// Generates a secret of size random bytes that signs with method and adds it with a new kid
+func (m *HSKeyManager) GenerateKey(size int, method jwt.SigningMethod) (string, error) {
+	secret, err := GenerateHMACSecret(size)
+	if err != nil {
+		return "", err
+	}
+	kid := NewKid()
+	if err := m.AddKeyWithMethod(kid, secret, method); err != nil {
+		return "", err
+	}
+	return kid, nil
+}
+
+type pemKeyManager interface {
+	Manager
+	AddKey(kid, path string)
+	AddPEMKey(kid string, pem []byte)
+}
+
+func addGeneratedKey(m pemKeyManager, kid string, key crypto.PrivateKey, path string) error {
+	if path != "" {
+		if err := WritePrivateKeyPEM(path, key); err != nil {
+			return err
+		}
+		m.AddKey(kid, path)
+		return nil
+	}
+	b, err := EncodePrivateKeyPEM(key)
+	if err != nil {
+		return err
+	}
+	m.AddPEMKey(kid, b)
+	return nil
+}
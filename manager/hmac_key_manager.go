@@ -3,23 +3,79 @@ package manager
 import (
 	"fmt"
 	"sync"
+
+	"github.com/golang-jwt/jwt"
 )
 
 type HSKeyManager struct {
-	Keys map[string]string
-	mu   sync.Mutex
+	Keys    map[string]string
+	methods map[string]jwt.SigningMethod
+	keyLifecycle
+	mu sync.Mutex
 }
 
 func NewHSKeyManager() *HSKeyManager {
 	return &HSKeyManager{
-		Keys: make(map[string]string),
+		Keys:    make(map[string]string),
+		methods: make(map[string]jwt.SigningMethod),
 	}
 }
 
+// Adds a key that signs with HS256
 func (m *HSKeyManager) AddKey(kid, secret string) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.Keys[kid] = secret
+	delete(m.methods, kid)
+	m.keyLifecycle.add(kid)
+}
+
+// Adds a key that can only be used with the given HS256, HS384 or HS512 signing method
+func (m *HSKeyManager) AddKeyWithMethod(kid, secret string, method jwt.SigningMethod) error {
+	if _, ok := method.(*jwt.SigningMethodHMAC); !ok {
+		return fmt.Errorf("signing method %s can not be used with HMAC keys", method.Alg())
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.methods == nil {
+		m.methods = make(map[string]jwt.SigningMethod)
+	}
+	m.Keys[kid] = secret
+	m.methods[kid] = method
+	m.keyLifecycle.add(kid)
+	return nil
+}
+
+// Adds a key that signs with HS256. The secret is loaded from the source when the key is added.
+func (m *HSKeyManager) AddKeySource(kid string, source KeySource) error {
+	secret, err := source.Load()
+	if err != nil {
+		return fmt.Errorf("error loading key %s: %w", kid, err)
+	}
+	m.AddKey(kid, string(secret))
+	return nil
+}
+
+// Adds a key from the source that can only be used with the given HS256, HS384 or HS512 signing method
+func (m *HSKeyManager) AddKeySourceWithMethod(kid string, source KeySource, method jwt.SigningMethod) error {
+	secret, err := source.Load()
+	if err != nil {
+		return fmt.Errorf("error loading key %s: %w", kid, err)
+	}
+	return m.AddKeyWithMethod(kid, string(secret), method)
+}
+
+func (m *HSKeyManager) GetSigningMethod(kid string) (jwt.SigningMethod, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.Keys[kid]; !exists {
+		return nil, fmt.Errorf("key does not exist")
+	}
+	if method, ok := m.methods[kid]; ok {
+		return method, nil
+	}
+	return jwt.SigningMethodHS256, nil
 }
 
 func (m *HSKeyManager) GetKey(kid string) ([]byte, error) {
@@ -31,3 +87,27 @@ func (m *HSKeyManager) GetKey(kid string) ([]byte, error) {
 	}
 	return []byte(key), nil
 }
+
+func (m *HSKeyManager) GetSigningKey(kid string) (interface{}, error) {
+	key, err := m.GetKey(kid)
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (m *HSKeyManager) GetKeyInfo(kid string) (KeyInfo, error) {
+	method, err := m.GetSigningMethod(kid)
+	if err != nil {
+		return KeyInfo{}, err
+	}
+	return m.keyLifecycle.keyInfo(kid, KeyTypeOct, method, nil)
+}
+
+func (m *HSKeyManager) RemoveKey(kid string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.Keys, kid)
+	delete(m.methods, kid)
+	m.keyLifecycle.remove(kid)
+}
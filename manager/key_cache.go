@@ -0,0 +1,168 @@
+package manager
+
+import (
+	"bytes"
+	"crypto"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Keeps the contents and the parsed private keys of a manager so that keys are not read
+// and parsed for every token. A key file is read again when its modification time or size changes.
+type keyCache struct {
+	entries     map[string]*cachedKey
+	passphrases map[string][]byte
+	mu          sync.Mutex
+}
+
+type cachedKey struct {
+	path    string
+	modTime time.Time
+	size    int64
+	raw     []byte
+	key     crypto.PrivateKey
+	// Set for the files of a watcher, which reloads them itself
+	watched bool
+}
+
+type parseKeyFunc func(pem []byte, passphrase []byte) (crypto.PrivateKey, error)
+
+// Returns the contents of the key file
+func (c *keyCache) read(kid, path string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, err := c.fileEntry(kid, path)
+	if err != nil {
+		return nil, err
+	}
+	return entry.raw, nil
+}
+
+// Returns the parsed key of the in-memory PEM, or of the key file when pem is nil
+func (c *keyCache) privateKey(kid string, pem []byte, path string, parse parseKeyFunc) (crypto.PrivateKey, error) {
+	if pem != nil {
+		return c.parsePEM(kid, pem, parse)
+	}
+	return c.parseFile(kid, path, parse)
+}
+
+func (c *keyCache) parseFile(kid, path string, parse parseKeyFunc) (crypto.PrivateKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, err := c.fileEntry(kid, path)
+	if err != nil {
+		return nil, err
+	}
+	return entry.parse(parse, c.passphrases[kid])
+}
+
+func (c *keyCache) parsePEM(kid string, pem []byte, parse parseKeyFunc) (crypto.PrivateKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[kid]
+	if !ok || entry.path != "" || !bytes.Equal(entry.raw, pem) {
+		entry = &cachedKey{raw: pem}
+		c.set(kid, entry)
+	}
+	return entry.parse(parse, c.passphrases[kid])
+}
+
+func (c *keyCache) fileEntry(kid, path string) (*cachedKey, error) {
+	if entry, ok := c.entries[kid]; ok && entry.watched && entry.path == path {
+		return entry, nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening file: %w", err)
+	}
+	if entry, ok := c.entries[kid]; ok && entry.path == path &&
+		entry.modTime.Equal(info.ModTime()) && entry.size == info.Size() {
+		return entry, nil
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening file: %w", err)
+	}
+	entry := &cachedKey{
+		path:    path,
+		modTime: info.ModTime(),
+		size:    info.Size(),
+		raw:     raw,
+	}
+	c.set(kid, entry)
+	return entry, nil
+}
+
+func (c *keyCache) set(kid string, entry *cachedKey) {
+	if c.entries == nil {
+		c.entries = make(map[string]*cachedKey)
+	}
+	c.entries[kid] = entry
+}
+
+// Keeps a key that was already parsed
+func (c *keyCache) store(kid string, pem []byte, key crypto.PrivateKey) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.set(kid, &cachedKey{raw: pem, key: key})
+}
+
+// Keeps a key file that was read and parsed by a watcher. The file is not read again until the
+// watcher stores it again, so that the previous key stays in use when the file can not be loaded.
+func (c *keyCache) storeWatched(kid, path string, raw []byte, key crypto.PrivateKey) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.set(kid, &cachedKey{
+		path:    path,
+		raw:     raw,
+		key:     key,
+		watched: true,
+	})
+}
+
+func (c *keyCache) invalidate(kid string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, kid)
+}
+
+// Drops the key and its passphrase
+func (c *keyCache) remove(kid string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, kid)
+	delete(c.passphrases, kid)
+}
+
+func (c *keyCache) passphrase(kid string) []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.passphrases[kid]
+}
+
+// Sets the passphrase of an encrypted key. The key is parsed again with it.
+func (c *keyCache) setPassphrase(kid string, passphrase []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.passphrases == nil {
+		c.passphrases = make(map[string][]byte)
+	}
+	c.passphrases[kid] = passphrase
+	if entry, ok := c.entries[kid]; ok {
+		entry.key = nil
+	}
+}
+
+func (e *cachedKey) parse(parse parseKeyFunc, passphrase []byte) (crypto.PrivateKey, error) {
+	if e.key == nil {
+		key, err := parse(e.raw, passphrase)
+		if err != nil {
+			return nil, err
+		}
+		e.key = key
+	}
+	return e.key, nil
+}
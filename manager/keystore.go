@@ -0,0 +1,221 @@
+package manager
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/Ashik80/oauth2jwtgen/keyutil"
+	"github.com/golang-jwt/jwt"
+)
+
+const keyStoreVersion = 1
+
+// Additional data of the sealed keys, so that they can not be mistaken for other data sealed with the master key
+var keyStoreAAD = []byte("oauth2jwtgen keystore v1")
+
+// Persists the keys of a manager and their lifecycle metadata to a file. The file is sealed with
+// AES-GCM under a master key, so the private key material is never written in the clear.
+// All keys are kept in the one file, storing them in a directory is not supported.
+type KeyStore struct {
+	path string
+	aead cipher.AEAD
+	mu   sync.Mutex
+}
+
+type keyStoreFile struct {
+	Version int    `json:"version"`
+	Sealed  []byte `json:"sealed"`
+}
+
+type storedKey struct {
+	Kid      string      `json:"kid"`
+	Alg      string      `json:"alg"`
+	Key      []byte      `json:"key"`
+	Metadata KeyMetadata `json:"metadata"`
+}
+
+// Implemented by managers whose keys can be added with a signing method other than the default one
+type methodKeySourceManager interface {
+	AddKeySourceWithMethod(kid string, source KeySource, method jwt.SigningMethod) error
+}
+
+// Creates a key store that keeps the keys in the file at path. The master key is a 16, 24
+// or 32 byte AES key, for example Base64Source(EnvSource("KEYSTORE_MASTER_KEY")).
+func NewKeyStore(path string, masterKey KeySource) (*KeyStore, error) {
+	key, err := masterKey.Load()
+	if err != nil {
+		return nil, fmt.Errorf("error loading master key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid master key: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyStore{
+		path: path,
+		aead: aead,
+	}, nil
+}
+
+// Writes every key of the manager with its signing method and lifecycle metadata, replacing
+// the keys that were stored before. Keys whose material is not available, such as the keys
+// of a SignerKeyManager, can not be stored. Encrypted PEM keys are stored decrypted, since
+// their passphrase is not stored, so their passphrase must be set before they are saved.
+func (s *KeyStore) Save(m Manager) error {
+	kids := m.KeyIDs()
+	keys := make([]storedKey, 0, len(kids))
+	for _, kid := range kids {
+		material, err := storedMaterial(m, kid)
+		if err != nil {
+			return fmt.Errorf("key %s can not be stored: %w", kid, err)
+		}
+		info, err := m.GetKeyInfo(kid)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, storedKey{
+			Kid:      kid,
+			Alg:      info.Alg,
+			Key:      material,
+			Metadata: info.Metadata,
+		})
+	}
+
+	plaintext, err := json.Marshal(keys)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	b, err := json.Marshal(keyStoreFile{
+		Version: keyStoreVersion,
+		Sealed:  s.aead.Seal(nonce, nonce, plaintext, keyStoreAAD),
+	})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return writeFileAtomic(s.path, b)
+}
+
+// Adds the stored keys to the manager with their signing methods and lifecycle metadata.
+// It does nothing when the file does not exist yet.
+func (s *KeyStore) Load(m Manager) error {
+	s.mu.Lock()
+	b, err := os.ReadFile(s.path)
+	s.mu.Unlock()
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error opening file: %w", err)
+	}
+
+	var file keyStoreFile
+	if err := json.Unmarshal(b, &file); err != nil {
+		return fmt.Errorf("invalid key store: %w", err)
+	}
+	if file.Version != keyStoreVersion {
+		return fmt.Errorf("unsupported key store version %d", file.Version)
+	}
+	if len(file.Sealed) < s.aead.NonceSize() {
+		return fmt.Errorf("invalid key store: sealed data is too short")
+	}
+	nonce, ciphertext := file.Sealed[:s.aead.NonceSize()], file.Sealed[s.aead.NonceSize():]
+	plaintext, err := s.aead.Open(nil, nonce, ciphertext, keyStoreAAD)
+	if err != nil {
+		return fmt.Errorf("failed to open key store, the master key may be wrong: %w", err)
+	}
+
+	var keys []storedKey
+	if err := json.Unmarshal(plaintext, &keys); err != nil {
+		return fmt.Errorf("invalid key store: %w", err)
+	}
+	for _, key := range keys {
+		if err := addStoredKey(m, key); err != nil {
+			return fmt.Errorf("error loading key %s: %w", key.Kid, err)
+		}
+	}
+	return nil
+}
+
+// Returns the material of the key, with an encrypted PEM replaced by the PKCS#8 PEM of the
+// decrypted key. The material is sealed under the master key, like every other key.
+func storedMaterial(m Manager, kid string) ([]byte, error) {
+	material, err := m.GetKey(kid)
+	if err != nil {
+		return nil, err
+	}
+	if !keyutil.IsEncrypted(material) {
+		return material, nil
+	}
+	privateKey, err := m.GetSigningKey(kid)
+	if err != nil {
+		return nil, err
+	}
+	return EncodePrivateKeyPEM(privateKey)
+}
+
+func addStoredKey(m Manager, key storedKey) error {
+	source := BytesSource(key.Key)
+	if man, ok := m.(methodKeySourceManager); ok {
+		method := jwt.GetSigningMethod(key.Alg)
+		if method == nil {
+			return fmt.Errorf("unknown signing method %s", key.Alg)
+		}
+		if err := man.AddKeySourceWithMethod(key.Kid, source, method); err != nil {
+			return err
+		}
+	} else if err := m.AddKeySource(key.Kid, source); err != nil {
+		return err
+	}
+
+	if man, ok := m.(LifecycleManager); ok {
+		return man.SetKeyMetadata(key.Kid, key.Metadata)
+	}
+	return nil
+}
+
+// Writes to a temporary file that is renamed over path, so that a crash never leaves a partial file
+func writeFileAtomic(path string, b []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error creating file: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	if err := f.Chmod(0600); err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return fmt.Errorf("error writing file: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("error writing file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+package manager
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/elliptic"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+var testMasterKey = BytesSource("0123456789abcdef0123456789abcdef")
+
+func newTestKeyStore(t *testing.T, path string, masterKey KeySource) *KeyStore {
+	t.Helper()
+	s, err := NewKeyStore(path, masterKey)
+	if err != nil {
+		t.Fatalf("NewKeyStore: %v", err)
+	}
+	return s
+}
+
+// Reports whether the signing keys of the two managers are the same key
+func sameSigningKey(t *testing.T, a, b Manager, kid string) bool {
+	t.Helper()
+	keyA, err := a.GetSigningKey(kid)
+	if err != nil {
+		t.Fatalf("GetSigningKey: %v", err)
+	}
+	keyB, err := b.GetSigningKey(kid)
+	if err != nil {
+		t.Fatalf("GetSigningKey of the loaded key: %v", err)
+	}
+	if secret, ok := keyA.([]byte); ok {
+		return bytes.Equal(secret, keyB.([]byte))
+	}
+	return keyA.(interface{ Equal(crypto.PrivateKey) bool }).Equal(keyB)
+}
+
+func TestKeyStoreRoundTrip(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	metadata := KeyMetadata{
+		CreatedAt:  now.Add(-2 * time.Hour),
+		ActivateAt: now.Add(-time.Hour),
+		RetireAt:   now.Add(time.Hour),
+		ExpireAt:   now.Add(2 * time.Hour),
+	}
+
+	tests := []struct {
+		name string
+		// Returns an empty manager of the type
+		newManager func() LifecycleManager
+		// Adds a key to the manager and returns its kid
+		generate func(m LifecycleManager) (string, error)
+		alg      string
+	}{
+		{
+			"HS384",
+			func() LifecycleManager { return NewHSKeyManager() },
+			func(m LifecycleManager) (string, error) {
+				return m.(*HSKeyManager).GenerateKey(48, jwt.SigningMethodHS384)
+			},
+			"HS384",
+		},
+		{
+			"PS256",
+			func() LifecycleManager { return NewRSKeyManager() },
+			func(m LifecycleManager) (string, error) {
+				return m.(*RSKeyManager).GenerateKey(2048, jwt.SigningMethodPS256, "")
+			},
+			"PS256",
+		},
+		{
+			"ES384",
+			func() LifecycleManager { return NewESKeyManager() },
+			func(m LifecycleManager) (string, error) {
+				return m.(*ESKeyManager).GenerateKey(elliptic.P384(), "")
+			},
+			"ES384",
+		},
+		{
+			"EdDSA",
+			func() LifecycleManager { return NewEdKeyManager() },
+			func(m LifecycleManager) (string, error) {
+				return m.(*EdKeyManager).GenerateKey("")
+			},
+			"EdDSA",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := tt.newManager()
+			kid, err := tt.generate(m)
+			if err != nil {
+				t.Fatalf("GenerateKey: %v", err)
+			}
+			if err := m.SetKeyMetadata(kid, metadata); err != nil {
+				t.Fatalf("SetKeyMetadata: %v", err)
+			}
+
+			s := newTestKeyStore(t, filepath.Join(t.TempDir(), "keystore.json"), testMasterKey)
+			if err := s.Save(m); err != nil {
+				t.Fatalf("Save: %v", err)
+			}
+			loaded := tt.newManager()
+			if err := s.Load(loaded); err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+
+			if kids := loaded.KeyIDs(); len(kids) != 1 || kids[0] != kid {
+				t.Fatalf("KeyIDs = %v, want [%s]", kids, kid)
+			}
+			info, err := loaded.GetKeyInfo(kid)
+			if err != nil {
+				t.Fatalf("GetKeyInfo: %v", err)
+			}
+			if info.Alg != tt.alg {
+				t.Errorf("alg = %s, want %s", info.Alg, tt.alg)
+			}
+			md := info.Metadata
+			if !md.CreatedAt.Equal(metadata.CreatedAt) || !md.ActivateAt.Equal(metadata.ActivateAt) ||
+				!md.RetireAt.Equal(metadata.RetireAt) || !md.ExpireAt.Equal(metadata.ExpireAt) {
+				t.Errorf("metadata = %+v, want %+v", md, metadata)
+			}
+			if !sameSigningKey(t, m, loaded, kid) {
+				t.Error("loaded key is not the saved key")
+			}
+		})
+	}
+}
+
+func TestKeyStoreEncryptedPEM(t *testing.T) {
+	m := NewRSKeyManager()
+	m.AddKey("key1", filepath.Join("testdata", "rsa-encrypted.pem"))
+
+	s := newTestKeyStore(t, filepath.Join(t.TempDir(), "keystore.json"), testMasterKey)
+	if err := s.Save(m); err == nil {
+		t.Fatal("Save succeeded without the passphrase of the encrypted key")
+	}
+
+	if err := m.SetPassphrase("key1", []byte("secret")); err != nil {
+		t.Fatalf("SetPassphrase: %v", err)
+	}
+	if err := s.Save(m); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	// The passphrase is not stored, so the key must be loadable without it
+	loaded := NewRSKeyManager()
+	if err := s.Load(loaded); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !sameSigningKey(t, m, loaded, "key1") {
+		t.Error("loaded key is not the saved key")
+	}
+}
+
+func TestKeyStoreLoadErrors(t *testing.T) {
+	m := NewHSKeyManager()
+	if _, err := m.GenerateKey(32, jwt.SigningMethodHS256); err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "keystore.json")
+	if err := newTestKeyStore(t, path, testMasterKey).Save(m); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	saved, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	// Rewrites the saved file, changed by edit
+	rewrite := func(t *testing.T, edit func(file *keyStoreFile)) {
+		t.Helper()
+		var file keyStoreFile
+		if err := json.Unmarshal(saved, &file); err != nil {
+			t.Fatalf("Unmarshal: %v", err)
+		}
+		edit(&file)
+		b, err := json.Marshal(file)
+		if err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+		if err := os.WriteFile(path, b, 0600); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		masterKey KeySource
+		edit      func(file *keyStoreFile)
+		want      string
+	}{
+		{"wrong master key", BytesSource("fedcba9876543210fedcba9876543210"), nil, "master key may be wrong"},
+		{"tampered", testMasterKey, func(file *keyStoreFile) { file.Sealed[len(file.Sealed)-1] ^= 1 }, "master key may be wrong"},
+		{"truncated", testMasterKey, func(file *keyStoreFile) { file.Sealed = file.Sealed[:4] }, "too short"},
+		{"version", testMasterKey, func(file *keyStoreFile) { file.Version = keyStoreVersion + 1 }, "unsupported key store version"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rewrite(t, func(file *keyStoreFile) {
+				if tt.edit != nil {
+					tt.edit(file)
+				}
+			})
+			loaded := NewHSKeyManager()
+			err := newTestKeyStore(t, path, tt.masterKey).Load(loaded)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Load: err = %v, want an error containing %q", err, tt.want)
+			}
+			if kids := loaded.KeyIDs(); len(kids) != 0 {
+				t.Errorf("KeyIDs = %v, want none", kids)
+			}
+		})
+	}
+}
+
+func TestKeyStoreMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keystore.json")
+	m := NewHSKeyManager()
+	if err := newTestKeyStore(t, path, testMasterKey).Load(m); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if kids := m.KeyIDs(); len(kids) != 0 {
+		t.Errorf("KeyIDs = %v, want none", kids)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Load created the file: %v", err)
+	}
+}
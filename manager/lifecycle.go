@@ -0,0 +1,243 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+type KeyStatus int
+
+const (
+	// The key is published but does not sign tokens yet
+	KeyPending KeyStatus = iota
+	// The key can sign tokens
+	KeyActive
+	// The key no longer signs tokens but the tokens it signed are still verified
+	KeyRetiring
+	// Every token the key signed has expired
+	KeyRetired
+)
+
+func (s KeyStatus) String() string {
+	switch s {
+	case KeyPending:
+		return "pending"
+	case KeyActive:
+		return "active"
+	case KeyRetiring:
+		return "retiring"
+	case KeyRetired:
+		return "retired"
+	}
+	return "unknown"
+}
+
+type KeyMetadata struct {
+	CreatedAt  time.Time
+	ActivateAt time.Time
+	// Zero while the key has not been retired
+	RetireAt time.Time
+	// Zero when the key verifies tokens until it is removed
+	ExpireAt time.Time
+}
+
+// Returns the metadata with ExpireAt set to tokenLifetime after RetireAt when the key was
+// retired without an expiry, so that it retires once every token it signed has expired
+func (md KeyMetadata) WithDefaultExpiry(tokenLifetime time.Duration) KeyMetadata {
+	if !md.RetireAt.IsZero() && md.ExpireAt.IsZero() {
+		md.ExpireAt = md.RetireAt.Add(tokenLifetime)
+	}
+	return md
+}
+
+func (md KeyMetadata) Status(now time.Time) KeyStatus {
+	if now.Before(md.ActivateAt) {
+		return KeyPending
+	}
+	if md.RetireAt.IsZero() || now.Before(md.RetireAt) {
+		return KeyActive
+	}
+	if md.ExpireAt.IsZero() || now.Before(md.ExpireAt) {
+		return KeyRetiring
+	}
+	return KeyRetired
+}
+
+// Implemented by managers that keep lifecycle metadata for their keys
+type LifecycleManager interface {
+	Manager
+	GetKeyMetadata(kid string) (KeyMetadata, error)
+	SetKeyMetadata(kid string, md KeyMetadata) error
+	RemoveKey(kid string)
+}
+
+// Returns the key that signs tokens, which is the active key that was activated last
+func ActiveKey(m Manager, now time.Time) (string, error) {
+	var activeKid string
+	var activeAt time.Time
+	for _, kid := range m.KeyIDs() {
+		info, err := m.GetKeyInfo(kid)
+		if err != nil {
+			continue
+		}
+		md := info.Metadata
+		if md.Status(now) != KeyActive {
+			continue
+		}
+		if activeKid == "" || md.ActivateAt.After(activeAt) {
+			activeKid = kid
+			activeAt = md.ActivateAt
+		}
+	}
+	if activeKid == "" {
+		return "", fmt.Errorf("no active key")
+	}
+	return activeKid, nil
+}
+
+// Retires the active keys that were superseded by a newer active key and removes the keys
+// that have retired. Retiring keys without an expiry keep verifying tokens for tokenLifetime.
+func Rotate(m LifecycleManager, now time.Time, tokenLifetime time.Duration) error {
+	activeKid, err := ActiveKey(m, now)
+	if err != nil {
+		return err
+	}
+	activeMd, err := m.GetKeyMetadata(activeKid)
+	if err != nil {
+		return err
+	}
+
+	for _, kid := range m.KeyIDs() {
+		if kid == activeKid {
+			continue
+		}
+		md, err := m.GetKeyMetadata(kid)
+		if err != nil {
+			continue
+		}
+
+		switch md.Status(now) {
+		case KeyActive:
+			md.RetireAt = activeMd.ActivateAt
+		case KeyRetired:
+			m.RemoveKey(kid)
+			continue
+		}
+		md = md.WithDefaultExpiry(tokenLifetime)
+		if err := m.SetKeyMetadata(kid, md); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Calls Rotate every interval until the context is done. Errors are passed to onError,
+// which can be nil.
+func StartRotation(ctx context.Context, m LifecycleManager, interval time.Duration, tokenLifetime time.Duration, onError func(error)) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				if err := Rotate(m, now, tokenLifetime); err != nil && onError != nil {
+					onError(err)
+				}
+			}
+		}
+	}()
+}
+
+// Lifecycle metadata of the keys of a manager. It is embedded in the key managers.
+type keyLifecycle struct {
+	metadata map[string]KeyMetadata
+	mu       sync.Mutex
+}
+
+func (l *keyLifecycle) add(kid string) {
+	l.addAt(kid, time.Now())
+}
+
+// Adds a key that is pending until activateAt, or active right away when it is in the past
+func (l *keyLifecycle) addAt(kid string, activateAt time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.metadata == nil {
+		l.metadata = make(map[string]KeyMetadata)
+	}
+	now := time.Now()
+	if activateAt.Before(now) {
+		activateAt = now
+	}
+	l.metadata[kid] = KeyMetadata{
+		CreatedAt:  now,
+		ActivateAt: activateAt,
+	}
+}
+
+func (l *keyLifecycle) remove(kid string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.metadata, kid)
+}
+
+func (l *keyLifecycle) KeyIDs() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	kids := make([]string, 0, len(l.metadata))
+	for kid := range l.metadata {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+	return kids
+}
+
+func (l *keyLifecycle) GetKeyMetadata(kid string) (KeyMetadata, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	md, exists := l.metadata[kid]
+	if !exists {
+		return KeyMetadata{}, fmt.Errorf("key does not exist")
+	}
+	return md, nil
+}
+
+func (l *keyLifecycle) SetKeyMetadata(kid string, md KeyMetadata) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, exists := l.metadata[kid]; !exists {
+		return fmt.Errorf("key does not exist")
+	}
+	if !md.RetireAt.IsZero() && md.RetireAt.Before(md.ActivateAt) {
+		return fmt.Errorf("key can not retire before it is activated")
+	}
+	if !md.ExpireAt.IsZero() && md.ExpireAt.Before(md.RetireAt) {
+		return fmt.Errorf("key can not expire before it is retired")
+	}
+	l.metadata[kid] = md
+	return nil
+}
+
+// Stops the key from signing tokens. It is still published so that the tokens it signed can be verified.
+func (l *keyLifecycle) RetireKey(kid string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	md, exists := l.metadata[kid]
+	if !exists {
+		return fmt.Errorf("key does not exist")
+	}
+	now := time.Now()
+	if md.RetireAt.IsZero() || md.RetireAt.After(now) {
+		md.RetireAt = now
+		if md.ActivateAt.After(now) {
+			md.ActivateAt = now
+		}
+		l.metadata[kid] = md
+	}
+	return nil
+}
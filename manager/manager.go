@@ -1,6 +1,84 @@
 package manager
 
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// Holds the keys that sign and verify tokens. Everything the server needs to sign with a key
+// and publish it is taken from this interface, so custom managers work like the ones of this package.
 type Manager interface {
-	AddKey(kid, secret string)
+	AddKeySource(kid string, source KeySource) error
 	GetKey(kid string) ([]byte, error)
+	KeyIDs() []string
+	GetKeyInfo(kid string) (KeyInfo, error)
+	GetSigningMethod(kid string) (jwt.SigningMethod, error)
+	// Returns the key that is passed to the signing method: the secret of an HMAC key,
+	// the parsed private key, or a crypto.Signer
+	GetSigningKey(kid string) (interface{}, error)
+}
+
+// Implemented by managers of asymmetric keys whose public halves can be published
+type PublicKeyManager interface {
+	Manager
+	PublicKeys() ([]PublicKey, error)
+}
+
+type PublicKey struct {
+	Kid string
+	Alg string
+	Key crypto.PublicKey
+}
+
+// Type of a key, named like the kty parameter of a JWK
+type KeyType string
+
+const (
+	KeyTypeOct KeyType = "oct"
+	KeyTypeRSA KeyType = "RSA"
+	KeyTypeEC  KeyType = "EC"
+	KeyTypeOKP KeyType = "OKP"
+)
+
+type KeyInfo struct {
+	Kid      string
+	Type     KeyType
+	Alg      string
+	Status   KeyStatus
+	Metadata KeyMetadata
+	// Nil for HMAC keys
+	PublicKey crypto.PublicKey
+}
+
+// Returns the type of the key a public key belongs to
+func PublicKeyType(publicKey crypto.PublicKey) (KeyType, bool) {
+	switch publicKey.(type) {
+	case *rsa.PublicKey:
+		return KeyTypeRSA, true
+	case *ecdsa.PublicKey:
+		return KeyTypeEC, true
+	case ed25519.PublicKey:
+		return KeyTypeOKP, true
+	}
+	return "", false
+}
+
+func (l *keyLifecycle) keyInfo(kid string, kty KeyType, method jwt.SigningMethod, publicKey crypto.PublicKey) (KeyInfo, error) {
+	md, err := l.GetKeyMetadata(kid)
+	if err != nil {
+		return KeyInfo{}, err
+	}
+	return KeyInfo{
+		Kid:       kid,
+		Type:      kty,
+		Alg:       method.Alg(),
+		Status:    md.Status(time.Now()),
+		Metadata:  md,
+		PublicKey: publicKey,
+	}, nil
 }
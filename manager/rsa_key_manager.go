@@ -1,38 +1,228 @@
 package manager
 
 import (
+	"crypto"
+	"crypto/rsa"
 	"fmt"
-	"os"
 	"sync"
+
+	"github.com/Ashik80/oauth2jwtgen/keyutil"
+	"github.com/golang-jwt/jwt"
 )
 
 type RSKeyManager struct {
-	Keys map[string]string
-	mu   sync.Mutex
+	Keys    map[string]string
+	pems    map[string][]byte
+	methods map[string]jwt.SigningMethod
+	cache   keyCache
+	keyLifecycle
+	mu sync.Mutex
 }
 
 func NewRSKeyManager() *RSKeyManager {
 	return &RSKeyManager{
-		Keys: make(map[string]string),
+		Keys:    make(map[string]string),
+		methods: make(map[string]jwt.SigningMethod),
 	}
 }
 
+// Adds a key that signs with RS256
 func (m *RSKeyManager) AddKey(kid, secret string) {
+	m.addKey(kid, secret, nil, nil)
+}
+
+// Adds a key that can only be used with the given RS256, RS384, RS512, PS256, PS384 or PS512 signing method
+func (m *RSKeyManager) AddKeyWithMethod(kid, secret string, method jwt.SigningMethod) error {
+	if err := checkRSASigningMethod(method); err != nil {
+		return err
+	}
+	m.addKey(kid, secret, nil, method)
+	return nil
+}
+
+// Adds a key from the source that signs with RS256. A FileSource is added as a path, other sources
+// are loaded when the key is added and kept in memory.
+func (m *RSKeyManager) AddKeySource(kid string, source KeySource) error {
+	return addPEMSource(m, kid, source)
+}
+
+// Adds a key from the source that can only be used with the given RS256, RS384, RS512, PS256, PS384 or PS512 signing method
+func (m *RSKeyManager) AddKeySourceWithMethod(kid string, source KeySource, method jwt.SigningMethod) error {
+	if err := checkRSASigningMethod(method); err != nil {
+		return err
+	}
+	if path, ok := source.(FileSource); ok {
+		m.addKey(kid, string(path), nil, method)
+		return nil
+	}
+	pem, err := source.Load()
+	if err != nil {
+		return fmt.Errorf("error loading key %s: %w", kid, err)
+	}
+	m.addKey(kid, "", pem, method)
+	return nil
+}
+
+func checkRSASigningMethod(method jwt.SigningMethod) error {
+	switch method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		return nil
+	case nil:
+		return fmt.Errorf("signing method is required")
+	}
+	return fmt.Errorf("signing method %s can not be used with RSA keys", method.Alg())
+}
+
+// Adds the key file at path, or the PEM when it is not nil, together with its signing method
+// so that the key is never used with another one. A nil method signs with RS256.
+func (m *RSKeyManager) addKey(kid, path string, pem []byte, method jwt.SigningMethod) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	m.Keys[kid] = secret
+	if m.pems == nil {
+		m.pems = make(map[string][]byte)
+	}
+	if m.methods == nil {
+		m.methods = make(map[string]jwt.SigningMethod)
+	}
+	if pem != nil {
+		delete(m.Keys, kid)
+		m.pems[kid] = pem
+	} else {
+		delete(m.pems, kid)
+		m.Keys[kid] = path
+	}
+	if method != nil {
+		m.methods[kid] = method
+	} else {
+		delete(m.methods, kid)
+	}
+	m.cache.invalidate(kid)
+	m.keyLifecycle.add(kid)
+}
+
+func (m *RSKeyManager) GetSigningMethod(kid string) (jwt.SigningMethod, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.hasKey(kid) {
+		return nil, fmt.Errorf("key does not exist")
+	}
+	if method, ok := m.methods[kid]; ok {
+		return method, nil
+	}
+	return jwt.SigningMethodRS256, nil
 }
 
 func (m *RSKeyManager) GetKey(kid string) ([]byte, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	if pem, exists := m.pems[kid]; exists {
+		return pem, nil
+	}
 	path, exists := m.Keys[kid]
 	if !exists {
 		return nil, fmt.Errorf("key does not exist")
 	}
-	key, err := os.ReadFile(path)
+	return m.cache.read(kid, path)
+}
+
+// Returns the parsed private key. The key is parsed once and parsed again only when its file changes.
+func (m *RSKeyManager) GetPrivateKey(kid string) (*rsa.PrivateKey, error) {
+	m.mu.Lock()
+	pem, isPem := m.pems[kid]
+	path, isFile := m.Keys[kid]
+	m.mu.Unlock()
+	if !isPem && !isFile {
+		return nil, fmt.Errorf("key does not exist")
+	}
+
+	key, err := m.cache.privateKey(kid, pem, path, parseRSAPrivateKey)
 	if err != nil {
-		return nil, fmt.Errorf("error opening file: %w", err)
+		return nil, err
+	}
+	return key.(*rsa.PrivateKey), nil
+}
+
+// Sets the passphrase that decrypts the key when it is an encrypted PKCS#8 PEM
+func (m *RSKeyManager) SetPassphrase(kid string, passphrase []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.hasKey(kid) {
+		return fmt.Errorf("key does not exist")
+	}
+	m.cache.setPassphrase(kid, passphrase)
+	return nil
+}
+
+// Adds a PEM encoded private key that is kept in memory instead of being read from a file
+func (m *RSKeyManager) AddPEMKey(kid string, pem []byte) {
+	m.addKey(kid, "", pem, nil)
+}
+
+func (m *RSKeyManager) hasKey(kid string) bool {
+	_, isFile := m.Keys[kid]
+	_, isPem := m.pems[kid]
+	return isFile || isPem
+}
+
+func (m *RSKeyManager) GetSigningKey(kid string) (interface{}, error) {
+	privateKey, err := m.GetPrivateKey(kid)
+	if err != nil {
+		return nil, err
+	}
+	return privateKey, nil
+}
+
+func (m *RSKeyManager) GetKeyInfo(kid string) (KeyInfo, error) {
+	privateKey, err := m.GetPrivateKey(kid)
+	if err != nil {
+		return KeyInfo{}, err
+	}
+	method, err := m.GetSigningMethod(kid)
+	if err != nil {
+		return KeyInfo{}, err
+	}
+	return m.keyLifecycle.keyInfo(kid, KeyTypeRSA, method, privateKey.Public())
+}
+
+func (m *RSKeyManager) RemoveKey(kid string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.Keys, kid)
+	delete(m.pems, kid)
+	delete(m.methods, kid)
+	m.cache.remove(kid)
+	m.keyLifecycle.remove(kid)
+}
+
+func (m *RSKeyManager) PublicKeys() ([]PublicKey, error) {
+	kids := m.KeyIDs()
+
+	keys := make([]PublicKey, 0, len(kids))
+	for _, kid := range kids {
+		privateKey, err := m.GetPrivateKey(kid)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", kid, err)
+		}
+		method, err := m.GetSigningMethod(kid)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, PublicKey{
+			Kid: kid,
+			Alg: method.Alg(),
+			Key: &privateKey.PublicKey,
+		})
+	}
+	return keys, nil
+}
+
+func parseRSAPrivateKey(pem []byte, passphrase []byte) (crypto.PrivateKey, error) {
+	privateKey, format, err := keyutil.ParsePrivateKey(pem, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := privateKey.(*rsa.PrivateKey); !ok {
+		return nil, fmt.Errorf("%s key is not an RSA key", format)
 	}
-	return key, nil
+	return privateKey, nil
 }
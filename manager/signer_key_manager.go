@@ -0,0 +1,172 @@
+package manager
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"fmt"
+	"sync"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// Manages keys whose private half never leaves a crypto.Signer, for example a key in
+// a KMS or an HSM. The manager only keeps the signer, the public key and the signing method.
+type SignerKeyManager struct {
+	signers    map[string]crypto.Signer
+	publicKeys map[string]crypto.PublicKey
+	methods    map[string]jwt.SigningMethod
+	keyLifecycle
+	mu sync.Mutex
+}
+
+func NewSignerKeyManager() *SignerKeyManager {
+	return &SignerKeyManager{
+		signers:    make(map[string]crypto.Signer),
+		publicKeys: make(map[string]crypto.PublicKey),
+		methods:    make(map[string]jwt.SigningMethod),
+	}
+}
+
+// Adds a signer that signs with method. The public key is taken from the signer when it is nil.
+func (m *SignerKeyManager) AddSigner(kid string, signer crypto.Signer, publicKey crypto.PublicKey, method jwt.SigningMethod) error {
+	if signer == nil {
+		return fmt.Errorf("signer is required")
+	}
+	if publicKey == nil {
+		publicKey = signer.Public()
+	}
+	if err := checkSigningMethod(publicKey, method); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.signers == nil {
+		m.signers = make(map[string]crypto.Signer)
+		m.publicKeys = make(map[string]crypto.PublicKey)
+		m.methods = make(map[string]jwt.SigningMethod)
+	}
+	m.signers[kid] = signer
+	m.publicKeys[kid] = publicKey
+	m.methods[kid] = method
+	m.keyLifecycle.add(kid)
+	return nil
+}
+
+// Always fails, signer keys are added with AddSigner
+func (m *SignerKeyManager) AddKeySource(kid string, source KeySource) error {
+	return fmt.Errorf("signer keys can only be added with AddSigner")
+}
+
+// Always fails because the private key is not available. Use GetSigner instead.
+func (m *SignerKeyManager) GetKey(kid string) ([]byte, error) {
+	return nil, fmt.Errorf("private key of signer %s is not available", kid)
+}
+
+func (m *SignerKeyManager) GetSigner(kid string) (crypto.Signer, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	signer, exists := m.signers[kid]
+	if !exists {
+		return nil, fmt.Errorf("key does not exist")
+	}
+	return signer, nil
+}
+
+func (m *SignerKeyManager) GetPublicKey(kid string) (crypto.PublicKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	publicKey, exists := m.publicKeys[kid]
+	if !exists {
+		return nil, fmt.Errorf("key does not exist")
+	}
+	return publicKey, nil
+}
+
+func (m *SignerKeyManager) GetSigningMethod(kid string) (jwt.SigningMethod, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	method, exists := m.methods[kid]
+	if !exists {
+		return nil, fmt.Errorf("key does not exist")
+	}
+	return method, nil
+}
+
+// Returns the signer of the key
+func (m *SignerKeyManager) GetSigningKey(kid string) (interface{}, error) {
+	signer, err := m.GetSigner(kid)
+	if err != nil {
+		return nil, err
+	}
+	return signer, nil
+}
+
+func (m *SignerKeyManager) GetKeyInfo(kid string) (KeyInfo, error) {
+	publicKey, err := m.GetPublicKey(kid)
+	if err != nil {
+		return KeyInfo{}, err
+	}
+	method, err := m.GetSigningMethod(kid)
+	if err != nil {
+		return KeyInfo{}, err
+	}
+	kty, _ := PublicKeyType(publicKey)
+	return m.keyLifecycle.keyInfo(kid, kty, method, publicKey)
+}
+
+func (m *SignerKeyManager) RemoveKey(kid string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.signers, kid)
+	delete(m.publicKeys, kid)
+	delete(m.methods, kid)
+	m.keyLifecycle.remove(kid)
+}
+
+func (m *SignerKeyManager) PublicKeys() ([]PublicKey, error) {
+	kids := m.KeyIDs()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	keys := make([]PublicKey, 0, len(kids))
+	for _, kid := range kids {
+		publicKey, exists := m.publicKeys[kid]
+		if !exists {
+			continue
+		}
+		keys = append(keys, PublicKey{
+			Kid: kid,
+			Alg: m.methods[kid].Alg(),
+			Key: publicKey,
+		})
+	}
+	return keys, nil
+}
+
+// Fails unless the signing method can be used with the type of the public key
+func checkSigningMethod(publicKey crypto.PublicKey, method jwt.SigningMethod) error {
+	if method == nil {
+		return fmt.Errorf("signing method is required")
+	}
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		switch method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+			return nil
+		}
+	case *ecdsa.PublicKey:
+		if expected, err := ESSigningMethod(key.Curve); err == nil && expected == method {
+			return nil
+		}
+	case ed25519.PublicKey:
+		if method == jwt.SigningMethodEdDSA {
+			return nil
+		}
+	default:
+		return fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+	return fmt.Errorf("signing method %s can not be used with a %T key", method.Alg(), publicKey)
+}
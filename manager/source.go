@@ -0,0 +1,91 @@
+package manager
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Where the material of a key is read from. Managers of PEM keys keep a FileSource as a path
+// so that the file can change, the other sources are loaded once when the key is added.
+type KeySource interface {
+	Load() ([]byte, error)
+}
+
+// Path of a file holding the key
+type FileSource string
+
+func (s FileSource) Load() ([]byte, error) {
+	b, err := os.ReadFile(string(s))
+	if err != nil {
+		return nil, fmt.Errorf("error opening file: %w", err)
+	}
+	return b, nil
+}
+
+// Key material given inline, such as a PEM or an HMAC secret
+type BytesSource []byte
+
+func (s BytesSource) Load() ([]byte, error) {
+	if len(s) == 0 {
+		return nil, fmt.Errorf("key is empty")
+	}
+	return s, nil
+}
+
+// Name of the environment variable holding the key
+type EnvSource string
+
+func (s EnvSource) Load() ([]byte, error) {
+	value, ok := os.LookupEnv(string(s))
+	if !ok {
+		return nil, fmt.Errorf("environment variable %s is not set", string(s))
+	}
+	if value == "" {
+		return nil, fmt.Errorf("environment variable %s is empty", string(s))
+	}
+	return []byte(value), nil
+}
+
+type base64Source struct {
+	source KeySource
+}
+
+// Decodes the key read from source as standard or URL base64, with or without padding
+func Base64Source(source KeySource) KeySource {
+	return &base64Source{source: source}
+}
+
+func (s *base64Source) Load() ([]byte, error) {
+	b, err := s.source.Load()
+	if err != nil {
+		return nil, err
+	}
+	encoded := strings.TrimSpace(string(b))
+	for _, encoding := range []*base64.Encoding{
+		base64.StdEncoding,
+		base64.RawStdEncoding,
+		base64.URLEncoding,
+		base64.RawURLEncoding,
+	} {
+		if decoded, err := encoding.DecodeString(encoded); err == nil {
+			return decoded, nil
+		}
+	}
+	return nil, fmt.Errorf("key is not base64 encoded")
+}
+
+// Adds the key of a manager of PEM keys. File sources are added as a path, the others as an in-memory PEM.
+func addPEMSource(m pemKeyManager, kid string, source KeySource) error {
+	if path, ok := source.(FileSource); ok {
+		m.AddKey(kid, string(path))
+		return nil
+	}
+	pem, err := source.Load()
+	if err != nil {
+		return fmt.Errorf("error loading key %s: %w", kid, err)
+	}
+	m.AddPEMKey(kid, pem)
+	return nil
+}
@@ -0,0 +1,217 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Called when a watched key file can not be loaded. kid is empty when the error is not about one key.
+type WatchErrorFunc func(kid string, err error)
+
+// Modification time and size of a key file when it was last loaded
+type fileState struct {
+	path    string
+	modTime time.Time
+	size    int64
+}
+
+type keyWatcher struct {
+	seen map[string]fileState
+	// Last error reported for each kid, so that an error is reported once and not on every check
+	failed map[string]string
+	// Keys that were retired because their file was deleted
+	deleted map[string]bool
+	onError WatchErrorFunc
+}
+
+func newKeyWatcher(onError WatchErrorFunc) *keyWatcher {
+	return &keyWatcher{
+		seen:    make(map[string]fileState),
+		failed:  make(map[string]string),
+		deleted: make(map[string]bool),
+		onError: onError,
+	}
+}
+
+// Reports whether the file of the key changed since it was last seen
+func (w *keyWatcher) changed(kid, path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		w.report(kid, fmt.Errorf("error opening file: %w", err))
+		return false
+	}
+	// Errors of a file that has not changed are only reported when it changes
+	w.recovered(kid)
+	state := fileState{
+		path:    path,
+		modTime: info.ModTime(),
+		size:    info.Size(),
+	}
+	if w.seen[kid] == state {
+		return false
+	}
+	w.seen[kid] = state
+	return true
+}
+
+// Passes the error to onError unless it is the one that was reported last for the kid
+func (w *keyWatcher) report(kid string, err error) {
+	if w.failed[kid] == err.Error() {
+		return
+	}
+	w.failed[kid] = err.Error()
+	if w.onError != nil {
+		w.onError(kid, err)
+	}
+}
+
+// Forgets the last error of the kid, so that it is reported again when it comes back
+func (w *keyWatcher) recovered(kid string) {
+	delete(w.failed, kid)
+}
+
+// Calls f every interval until ctx is done
+func poll(ctx context.Context, interval time.Duration, f func()) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				f()
+			}
+		}
+	}()
+}
+
+// Checks the key files of the manager every interval and swaps in a key when its file changes.
+// The keys are loaded before it returns. When a file can not be read or parsed the error is
+// passed to onError once and the previous key stays in use. Only RSKeyManager can watch its
+// keys, the files of the other managers are read again when they change but are not watched.
+func (m *RSKeyManager) WatchKeys(ctx context.Context, interval time.Duration, onError WatchErrorFunc) {
+	w := newKeyWatcher(onError)
+	m.reloadKeys(w)
+	poll(ctx, interval, func() { m.reloadKeys(w) })
+}
+
+// Checks dir every interval for <kid>.pem files. The keys in dir are loaded and activated before
+// it returns. Keys whose file appears later are pending for activationDelay, so that clients fetch
+// them from the JWKS endpoint before tokens are signed with them. A key is swapped in when its file
+// changes and retired when it is deleted, so that the tokens it signed can still be verified.
+// When a file can not be read or parsed the error is passed to onError once and the previous key stays in use.
+func (m *RSKeyManager) WatchDir(ctx context.Context, dir string, interval time.Duration, activationDelay time.Duration, onError WatchErrorFunc) {
+	w := newKeyWatcher(onError)
+	m.reloadDir(w, dir, 0)
+	poll(ctx, interval, func() { m.reloadDir(w, dir, activationDelay) })
+}
+
+func (m *RSKeyManager) reloadKeys(w *keyWatcher) {
+	m.mu.Lock()
+	paths := make(map[string]string, len(m.Keys))
+	for kid, path := range m.Keys {
+		paths[kid] = path
+	}
+	m.mu.Unlock()
+
+	for kid, path := range paths {
+		if !w.changed(kid, path) {
+			continue
+		}
+		if err := m.reloadKey(kid, path, reloadSwap, time.Time{}); err != nil {
+			w.report(kid, err)
+		}
+	}
+}
+
+func (m *RSKeyManager) reloadDir(w *keyWatcher, dir string, activationDelay time.Duration) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		w.report("", fmt.Errorf("error reading directory: %w", err))
+		return
+	}
+	w.recovered("")
+
+	activateAt := time.Now().Add(activationDelay)
+	found := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".pem") {
+			continue
+		}
+		kid := strings.TrimSuffix(entry.Name(), ".pem")
+		path := filepath.Join(dir, entry.Name())
+		found[kid] = true
+		if !w.changed(kid, path) {
+			continue
+		}
+		mode := reloadAdd
+		if w.deleted[kid] {
+			mode = reloadReadd
+		}
+		if err := m.reloadKey(kid, path, mode, activateAt); err != nil {
+			w.report(kid, err)
+			continue
+		}
+		delete(w.deleted, kid)
+	}
+
+	for kid, state := range w.seen {
+		if found[kid] {
+			continue
+		}
+		delete(w.seen, kid)
+		m.mu.Lock()
+		path, exists := m.Keys[kid]
+		m.mu.Unlock()
+		if exists && path == state.path {
+			// The key stays in memory until it is removed by Rotate
+			m.RetireKey(kid)
+			w.deleted[kid] = true
+		}
+	}
+}
+
+// How reloadKey treats a key that the manager does not have with the same path
+type reloadMode int
+
+const (
+	// Only swaps in the keys of the manager, a key that was removed or replaced is left alone
+	reloadSwap reloadMode = iota
+	// Adds the key when the manager does not have it
+	reloadAdd
+	// Adds the key again when its file reappears after the key was retired
+	reloadReadd
+)
+
+// Reads and parses the key file and swaps it in. Keys that are added become active at activateAt.
+// The key stays a file key, only the cached contents of its file are replaced.
+func (m *RSKeyManager) reloadKey(kid, path string, mode reloadMode, activateAt time.Time) error {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error opening file: %w", err)
+	}
+	privateKey, err := parseRSAPrivateKey(pem, m.cache.passphrase(kid))
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	current, exists := m.Keys[kid]
+	_, isPem := m.pems[kid]
+	if exists && current != path || !exists && (mode == reloadSwap || isPem) {
+		return nil
+	}
+	if !exists || mode == reloadReadd {
+		delete(m.methods, kid)
+		m.Keys[kid] = path
+		m.keyLifecycle.addAt(kid, activateAt)
+	}
+	m.cache.storeWatched(kid, path, pem, privateKey)
+	return nil
+}
@@ -1,6 +1,9 @@
 package options
 
 import (
+	"log"
+	"slices"
+	"strings"
 	"sync"
 
 	"github.com/Ashik80/oauth2jwtgen/claims"
@@ -9,8 +12,17 @@ import (
 )
 
 type AuthOptions struct {
+	// Used as the iss claim of the tokens. Defaults to the host of the request.
+	// OpenID Connect clients expect the https URL the discovery document is served from,
+	// and the discovery document is only served when it is set.
+	Issuer               string
 	Validity             *Validity
 	Store                store.TokenStore
+	ClientStore          store.ClientStore
+	AuthCodeStore        store.AuthCodeStore
+	RevokedTokenStore    store.RevokedTokenStore
+	RequireOpenIDScope   bool
+	ErrorLog             *log.Logger
 	refreshInCookie      bool
 	accessInCookie       bool
 	refreshCookieOptions *CookieOptions
@@ -20,6 +32,16 @@ type AuthOptions struct {
 	mu                   sync.Mutex
 }
 
+// Logs the errors that are not returned to the client, such as the keys that the JWKS endpoint
+// skips because they can not be read. The standard logger is used when ErrorLog is nil.
+func (s *AuthOptions) Logf(format string, args ...interface{}) {
+	if s.ErrorLog != nil {
+		s.ErrorLog.Printf(format, args...)
+		return
+	}
+	log.Printf(format, args...)
+}
+
 func DefaultAuthOptions() *AuthOptions {
 	v := new(Validity)
 	v.SetDefaultAccessExpiresIn()
@@ -52,6 +74,16 @@ func (s *AuthOptions) IsIdTokenClaimsSet(username string) bool {
 	return exists
 }
 
+// Reports whether an id token may be issued with a token that has the scope and client_id. OpenID Connect
+// only issues id tokens when the openid scope was granted. Tokens issued to a client always require it,
+// tokens of the password grant only when RequireOpenIDScope is set.
+func (s *AuthOptions) IsIdTokenAllowed(scope string, clientId string) bool {
+	if !s.RequireOpenIDScope && clientId == "" {
+		return true
+	}
+	return slices.Contains(strings.Fields(scope), "openid")
+}
+
 func (s *AuthOptions) GetIdTokenClaims(username string) *claims.JWTIdClaims {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -72,7 +104,7 @@ func (s *AuthOptions) SetRefreshTokenInCookie(cookieOptions *CookieOptions) {
 func (s *AuthOptions) SetAccessTokenInCookie(cookieOptions *CookieOptions) {
 	s.accessInCookie = true
 	s.accessCookieOptions = new(CookieOptions)
-	s.refreshCookieOptions = cookieOptions
+	s.accessCookieOptions = cookieOptions
 	s.accessCookieOptions.SetName("access_token")
 	if s.accessCookieOptions.MaxAge == 0 {
 		s.accessCookieOptions.MaxAge = int(s.Validity.AccessExpiresIn)
@@ -3,6 +3,7 @@ package options
 type Validity struct {
 	AccessExpiresIn  int64
 	RefreshExpiresIn int
+	CodeExpiresIn    int64
 }
 
 func (v *Validity) GetAccessExpiresIn() int64 {
@@ -13,6 +14,10 @@ func (v *Validity) GetRefreshExpiresIn() int {
 	return v.RefreshExpiresIn
 }
 
+func (v *Validity) GetCodeExpiresIn() int64 {
+	return v.CodeExpiresIn
+}
+
 func (v *Validity) SetDefaultAccessExpiresIn() {
 	v.AccessExpiresIn = 10 * 60
 }
@@ -20,3 +25,7 @@ func (v *Validity) SetDefaultAccessExpiresIn() {
 func (v *Validity) SetDefaultRefreshExpiresIn() {
 	v.RefreshExpiresIn = 60 * 60
 }
+
+func (v *Validity) SetDefaultCodeExpiresIn() {
+	v.CodeExpiresIn = 60
+}
@@ -0,0 +1,252 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"time"
+
+	"github.com/Ashik80/oauth2jwtgen/options"
+	"github.com/Ashik80/oauth2jwtgen/store"
+)
+
+const (
+	CodeChallengeMethodS256  = "S256"
+	CodeChallengeMethodPlain = "plain"
+)
+
+var codeVerifierPattern = regexp.MustCompile(`^[A-Za-z0-9\-._~]{43,128}$`)
+
+// Called by the authorize endpoint to authenticate the resource owner. It returns the
+// username of the logged in user. If the user still has to log in the callback writes
+// the response itself (for example a redirect to a login page) and returns an empty username.
+type AuthorizeCallbackFunc func(w http.ResponseWriter, r *http.Request, opt *options.AuthOptions) (string, *CallbackError)
+
+func (o *OAuthServer) Authorize(f AuthorizeCallbackFunc) http.HandlerFunc {
+	o.mu.Lock()
+	o.responseTypes = appendUnique(o.responseTypes, "code")
+	o.mu.Unlock()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		if err := r.ParseForm(); err != nil {
+			WriteError(w, http.StatusBadRequest, "invalid_request", err.Error())
+			return
+		}
+
+		if o.options.ClientStore == nil {
+			WriteError(w, http.StatusInternalServerError, "server_error", "client store not specified")
+			return
+		}
+
+		// Errors about the client or the redirect uri must not be redirected back to it
+		clientId := r.FormValue("client_id")
+		client, err := o.options.ClientStore.GetClient(ctx, clientId)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, "invalid_client", err.Error())
+			return
+		}
+
+		redirectUri := r.FormValue("redirect_uri")
+		if !client.HasRedirectUri(redirectUri) {
+			WriteError(w, http.StatusBadRequest, "invalid_request", "redirect_uri does not match a registered uri")
+			return
+		}
+
+		state := r.FormValue("state")
+
+		if r.FormValue("response_type") != "code" {
+			redirectError(w, r, redirectUri, state, "unsupported_response_type", "")
+			return
+		}
+
+		scope, err := resolveScope(client, r.FormValue("scope"))
+		if err != nil {
+			redirectError(w, r, redirectUri, state, "invalid_scope", err.Error())
+			return
+		}
+
+		codeChallenge := r.FormValue("code_challenge")
+		codeChallengeMethod := r.FormValue("code_challenge_method")
+		if codeChallenge == "" && client.Public {
+			redirectError(w, r, redirectUri, state, "invalid_request", "code_challenge is required")
+			return
+		}
+		if codeChallenge != "" {
+			if codeChallengeMethod == "" {
+				codeChallengeMethod = CodeChallengeMethodPlain
+			}
+			if codeChallengeMethod != CodeChallengeMethodS256 && codeChallengeMethod != CodeChallengeMethodPlain {
+				redirectError(w, r, redirectUri, state, "invalid_request", "unsupported code_challenge_method")
+				return
+			}
+		}
+
+		username, cbErr := f(w, r, o.options)
+		if cbErr != nil {
+			redirectError(w, r, redirectUri, state, "access_denied", cbErr.Error())
+			return
+		}
+		if username == "" {
+			return
+		}
+
+		code, err := generateCode()
+		if err != nil {
+			redirectError(w, r, redirectUri, state, "server_error", err.Error())
+			return
+		}
+
+		authCode := &store.AuthCode{
+			Code:                code,
+			ClientId:            client.Id,
+			RedirectUri:         redirectUri,
+			ResourceOwnerId:     username,
+			Scope:               scope,
+			Nonce:               r.FormValue("nonce"),
+			CodeChallenge:       codeChallenge,
+			CodeChallengeMethod: codeChallengeMethod,
+			Expiry:              time.Now().Add(time.Duration(o.options.Validity.CodeExpiresIn) * time.Second),
+		}
+		if err := o.options.AuthCodeStore.StoreAuthCode(ctx, authCode); err != nil {
+			redirectError(w, r, redirectUri, state, "server_error", err.Error())
+			return
+		}
+
+		params := url.Values{}
+		params.Set("code", code)
+		if state != "" {
+			params.Set("state", state)
+		}
+		http.Redirect(w, r, withQuery(redirectUri, params), http.StatusFound)
+	}
+}
+
+// Handles the authorization_code grant of the token endpoint
+func (o *OAuthServer) AuthorizationCode() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		if err := r.ParseForm(); err != nil {
+			WriteError(w, http.StatusBadRequest, "invalid_request", err.Error())
+			return
+		}
+
+		if r.FormValue("grant_type") != GrantTypeAuthorizationCode {
+			WriteError(w, http.StatusBadRequest, "unsupported_grant_type", "")
+			return
+		}
+
+		client, err := o.authenticateClient(ctx, r)
+		if err != nil {
+			WriteError(w, http.StatusUnauthorized, "invalid_client", err.Error())
+			return
+		}
+
+		code := r.FormValue("code")
+		if code == "" {
+			WriteError(w, http.StatusBadRequest, "invalid_request", "code is required")
+			return
+		}
+
+		authCode, err := o.options.AuthCodeStore.ConsumeAuthCode(ctx, code)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, "invalid_grant", err.Error())
+			return
+		}
+
+		if authCode.Expiry.Before(time.Now()) {
+			WriteError(w, http.StatusBadRequest, "invalid_grant", "authorization code expired")
+			return
+		}
+		if authCode.ClientId != client.Id {
+			WriteError(w, http.StatusBadRequest, "invalid_grant", "authorization code was issued to another client")
+			return
+		}
+		if authCode.RedirectUri != r.FormValue("redirect_uri") {
+			WriteError(w, http.StatusBadRequest, "invalid_grant", "redirect_uri does not match")
+			return
+		}
+		if err := verifyCodeChallenge(authCode, r.FormValue("code_verifier")); err != nil {
+			WriteError(w, http.StatusBadRequest, "invalid_grant", err.Error())
+			return
+		}
+
+		access, err := o.newAccess()
+		if err != nil {
+			WriteError(w, http.StatusInternalServerError, "server_error", err.Error())
+			return
+		}
+
+		token, err := o.newToken(ctx, access, authCode.ResourceOwnerId, o.issuer(r), client.Id, client.Id, authCode.Scope, authCode.Nonce)
+		if err != nil {
+			WriteError(w, http.StatusInternalServerError, "server_error", err.Error())
+			return
+		}
+
+		o.setTokenCookies(w, token)
+		writeToken(w, token)
+	}
+}
+
+func verifyCodeChallenge(authCode *store.AuthCode, codeVerifier string) error {
+	if authCode.CodeChallenge == "" {
+		if codeVerifier != "" {
+			return fmt.Errorf("code_verifier was sent without a code_challenge")
+		}
+		return nil
+	}
+
+	if !codeVerifierPattern.MatchString(codeVerifier) {
+		return fmt.Errorf("invalid code_verifier")
+	}
+
+	challenge := codeVerifier
+	if authCode.CodeChallengeMethod == CodeChallengeMethodS256 {
+		sum := sha256.Sum256([]byte(codeVerifier))
+		challenge = base64.RawURLEncoding.EncodeToString(sum[:])
+	}
+	if subtle.ConstantTimeCompare([]byte(challenge), []byte(authCode.CodeChallenge)) != 1 {
+		return fmt.Errorf("code_verifier does not match the code_challenge")
+	}
+	return nil
+}
+
+func generateCode() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate code: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func redirectError(w http.ResponseWriter, r *http.Request, redirectUri, state, code, description string) {
+	params := url.Values{}
+	params.Set("error", code)
+	if description != "" {
+		params.Set("error_description", description)
+	}
+	if state != "" {
+		params.Set("state", state)
+	}
+	http.Redirect(w, r, withQuery(redirectUri, params), http.StatusFound)
+}
+
+func withQuery(uri string, params url.Values) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return uri
+	}
+	q := u.Query()
+	for k, v := range params {
+		q[k] = v
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
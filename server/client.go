@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Ashik80/oauth2jwtgen/store"
+	"github.com/golang-jwt/jwt"
+)
+
+// Authenticates the client with HTTP basic auth or the client_id and client_secret form values.
+// Public clients only have to identify themselves with client_id.
+func (o *OAuthServer) authenticateClient(ctx context.Context, r *http.Request) (*store.Client, error) {
+	if o.options.ClientStore == nil {
+		return nil, fmt.Errorf("client store not specified")
+	}
+
+	clientId, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientId = r.FormValue("client_id")
+		clientSecret = r.FormValue("client_secret")
+	}
+	if clientId == "" {
+		return nil, fmt.Errorf("client_id is required")
+	}
+
+	client, err := o.options.ClientStore.GetClient(ctx, clientId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client: %w", err)
+	}
+
+	if client.Public {
+		return client, nil
+	}
+	if subtle.ConstantTimeCompare([]byte(client.Secret), []byte(clientSecret)) != 1 {
+		return nil, fmt.Errorf("invalid client credentials")
+	}
+	return client, nil
+}
+
+// Reports whether the client may use a token with the claims. Tokens issued to a client belong to it
+// alone. Tokens without a client_id, such as the ones of the password grant, can be used by any
+// authenticated client, or by whoever presents them when there is no client store.
+func canUseToken(mapClaims jwt.MapClaims, client *store.Client) bool {
+	clientId := stringClaim(mapClaims, "client_id")
+	if clientId == "" {
+		return true
+	}
+	return client != nil && client.Id == clientId
+}
+
+// Returns the requested scope if the client is allowed all of it, or every scope
+// the client is allowed when none was requested
+func resolveScope(client *store.Client, requested string) (string, error) {
+	if requested == "" {
+		return strings.Join(client.Scopes, " "), nil
+	}
+	for _, scope := range strings.Fields(requested) {
+		if !client.HasScope(scope) {
+			return "", fmt.Errorf("scope %s is not allowed for the client", scope)
+		}
+	}
+	return strings.Join(strings.Fields(requested), " "), nil
+}
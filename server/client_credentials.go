@@ -0,0 +1,68 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/Ashik80/oauth2jwtgen/accessor"
+	"github.com/Ashik80/oauth2jwtgen/claims"
+)
+
+// Handles the client_credentials grant of the token endpoint. The client is the subject
+// of the token so no id token or refresh token is issued.
+func (o *OAuthServer) ClientCredentials() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		if err := r.ParseForm(); err != nil {
+			WriteError(w, http.StatusBadRequest, "invalid_request", err.Error())
+			return
+		}
+
+		if r.FormValue("grant_type") != GrantTypeClientCredentials {
+			WriteError(w, http.StatusBadRequest, "unsupported_grant_type", "")
+			return
+		}
+
+		client, err := o.authenticateClient(ctx, r)
+		if err != nil {
+			WriteError(w, http.StatusUnauthorized, "invalid_client", err.Error())
+			return
+		}
+		if client.Public {
+			WriteError(w, http.StatusBadRequest, "unauthorized_client", "public clients can not use the client_credentials grant")
+			return
+		}
+
+		scope, err := resolveScope(client, r.FormValue("scope"))
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, "invalid_scope", err.Error())
+			return
+		}
+
+		access, err := o.newAccess()
+		if err != nil {
+			WriteError(w, http.StatusInternalServerError, "server_error", err.Error())
+			return
+		}
+
+		key, err := accessor.GetParsedSigningKey(access)
+		if err != nil {
+			WriteError(w, http.StatusInternalServerError, "server_error", err.Error())
+			return
+		}
+
+		accessClaims := claims.GenerateAccessClaims(client.Id, o.issuer(r), client.Id, scope, nil, o.options.Validity.AccessExpiresIn)
+		accessClaims.ClientId = client.Id
+		accessToken, err := accessor.GenerateTokenString(access, accessClaims, key)
+		if err != nil {
+			WriteError(w, http.StatusInternalServerError, "server_error", err.Error())
+			return
+		}
+
+		writeToken(w, &accessor.Token{
+			AccessToken: accessToken,
+			TokenType:   "Bearer",
+			ExpiresIn:   o.options.Validity.AccessExpiresIn,
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/Ashik80/oauth2jwtgen/claims"
+)
+
+// Paths the endpoints are mounted on. Relative paths are resolved against the issuer
+// and endpoints that are not mounted are left empty.
+type DiscoveryOptions struct {
+	AuthorizationEndpoint string
+	TokenEndpoint         string
+	UserInfoEndpoint      string
+	JWKSUri               string
+	IntrospectionEndpoint string
+	RevocationEndpoint    string
+	ScopesSupported       []string
+}
+
+// OpenID Provider Metadata as described in OpenID Connect Discovery 1.0 section 3
+type OpenIDConfiguration struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint,omitempty"`
+	TokenEndpoint                     string   `json:"token_endpoint,omitempty"`
+	UserInfoEndpoint                  string   `json:"userinfo_endpoint,omitempty"`
+	JWKSUri                           string   `json:"jwks_uri,omitempty"`
+	IntrospectionEndpoint             string   `json:"introspection_endpoint,omitempty"`
+	RevocationEndpoint                string   `json:"revocation_endpoint,omitempty"`
+	ScopesSupported                   []string `json:"scopes_supported,omitempty"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported,omitempty"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IdTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported,omitempty"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported,omitempty"`
+	ClaimsSupported                   []string `json:"claims_supported,omitempty"`
+}
+
+// Serves the OpenID Connect discovery document. Mount it on /.well-known/openid-configuration
+// after the other endpoints so that the grant types they support are known. The Issuer option
+// must be set, otherwise the document is answered with server_error.
+func (o *OAuthServer) OpenIDConfiguration(opt *DiscoveryOptions) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		config, err := o.openIDConfiguration(opt)
+		if err != nil {
+			o.options.Logf("discovery: %v", err)
+			WriteError(w, http.StatusInternalServerError, "server_error", "")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(config)
+	}
+}
+
+func (o *OAuthServer) openIDConfiguration(opt *DiscoveryOptions) (*OpenIDConfiguration, error) {
+	// The host of the request is not a URL that clients can check the issuer against
+	issuer := o.options.Issuer
+	if u, err := url.Parse(issuer); err != nil || !u.IsAbs() || u.Host == "" {
+		return nil, fmt.Errorf("the issuer option must be set to an absolute URL to serve the discovery document")
+	}
+
+	algs, err := o.signingAlgs()
+	if err != nil {
+		return nil, err
+	}
+
+	o.mu.Lock()
+	grantTypes := append([]string{}, o.grantTypes...)
+	responseTypes := append([]string{}, o.responseTypes...)
+	o.mu.Unlock()
+	sort.Strings(grantTypes)
+
+	config := &OpenIDConfiguration{
+		Issuer:                            issuer,
+		AuthorizationEndpoint:             endpointUrl(issuer, opt.AuthorizationEndpoint),
+		TokenEndpoint:                     endpointUrl(issuer, opt.TokenEndpoint),
+		UserInfoEndpoint:                  endpointUrl(issuer, opt.UserInfoEndpoint),
+		JWKSUri:                           endpointUrl(issuer, opt.JWKSUri),
+		IntrospectionEndpoint:             endpointUrl(issuer, opt.IntrospectionEndpoint),
+		RevocationEndpoint:                endpointUrl(issuer, opt.RevocationEndpoint),
+		ScopesSupported:                   opt.ScopesSupported,
+		ResponseTypesSupported:            responseTypes,
+		GrantTypesSupported:               grantTypes,
+		SubjectTypesSupported:             []string{"public"},
+		IdTokenSigningAlgValuesSupported:  algs,
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		ClaimsSupported:                   idTokenClaimNames(),
+	}
+	if len(config.ResponseTypesSupported) == 0 {
+		// The field is required even when the authorize endpoint is not mounted
+		config.ResponseTypesSupported = []string{}
+	} else {
+		config.CodeChallengeMethodsSupported = []string{CodeChallengeMethodS256, CodeChallengeMethodPlain}
+	}
+	return config, nil
+}
+
+// Returns the algorithms of the signing key and of every published key. Keys that can not be
+// read are left out and logged like on the JWKS endpoint.
+func (o *OAuthServer) signingAlgs() ([]string, error) {
+	signingKid, err := o.signingKid()
+	if err != nil {
+		return nil, err
+	}
+	method, err := o.kmanager.GetSigningMethod(signingKid)
+	if err != nil {
+		return nil, err
+	}
+	algs := []string{method.Alg()}
+
+	for _, kid := range o.kmanager.KeyIDs() {
+		info, err := o.kmanager.GetKeyInfo(kid)
+		if err != nil {
+			o.options.Logf("discovery: skipping key %s: %v", kid, err)
+			continue
+		}
+		if info.PublicKey != nil {
+			algs = appendUnique(algs, info.Alg)
+		}
+	}
+	return algs, nil
+}
+
+func endpointUrl(issuer string, path string) string {
+	if path == "" {
+		return ""
+	}
+	if u, err := url.Parse(path); err == nil && u.IsAbs() {
+		return path
+	}
+	return strings.TrimSuffix(issuer, "/") + "/" + strings.TrimPrefix(path, "/")
+}
+
+// Returns the JSON names of the id token claims
+func idTokenClaimNames() []string {
+	names := []string{"iss", "sub", "aud", "exp", "iat"}
+	t := reflect.TypeOf(claims.JWTIdClaims{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names = appendUnique(names, name)
+		}
+	}
+	return names
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
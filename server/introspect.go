@@ -0,0 +1,153 @@
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	TokenTypeHintAccessToken  = "access_token"
+	TokenTypeHintRefreshToken = "refresh_token"
+)
+
+// Introspection response as described in RFC 7662 section 2.2
+type IntrospectionResponse struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientId  string `json:"client_id,omitempty"`
+	Username  string `json:"username,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	Exp       int64  `json:"exp,omitempty"`
+	Iat       int64  `json:"iat,omitempty"`
+	Nbf       int64  `json:"nbf,omitempty"`
+	Sub       string `json:"sub,omitempty"`
+	Aud       string `json:"aud,omitempty"`
+	Iss       string `json:"iss,omitempty"`
+	Jti       string `json:"jti,omitempty"`
+}
+
+// Handles token introspection requests. Only registered confidential clients can introspect tokens.
+func (o *OAuthServer) Introspect() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		if err := r.ParseForm(); err != nil {
+			WriteError(w, http.StatusBadRequest, "invalid_request", err.Error())
+			return
+		}
+
+		client, err := o.authenticateClient(ctx, r)
+		if err != nil || client.Public {
+			w.Header().Set("WWW-Authenticate", `Basic realm="introspect"`)
+			WriteError(w, http.StatusUnauthorized, "invalid_client", "client authentication failed")
+			return
+		}
+
+		token := r.FormValue("token")
+		if token == "" {
+			WriteError(w, http.StatusBadRequest, "invalid_request", "token is required")
+			return
+		}
+
+		var resp *IntrospectionResponse
+		if r.FormValue("token_type_hint") == TokenTypeHintRefreshToken {
+			resp = o.introspectRefreshToken(r, token)
+			if !resp.Active {
+				resp = o.introspectAccessToken(r, token)
+			}
+		} else {
+			resp = o.introspectAccessToken(r, token)
+			if !resp.Active {
+				resp = o.introspectRefreshToken(r, token)
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+func (o *OAuthServer) introspectAccessToken(r *http.Request, tokenString string) *IntrospectionResponse {
+	mapClaims, err := o.tokenVerifier().Verify(tokenString)
+	if err != nil {
+		return &IntrospectionResponse{Active: false}
+	}
+
+	resp := introspectionFromClaims(mapClaims)
+	resp.Active = true
+	resp.TokenType = "Bearer"
+	return resp
+}
+
+func (o *OAuthServer) introspectRefreshToken(r *http.Request, refreshToken string) *IntrospectionResponse {
+	idBytes, err := base64.URLEncoding.DecodeString(refreshToken)
+	if err != nil {
+		return &IntrospectionResponse{Active: false}
+	}
+	tokenInfo, err := o.options.Store.GetTokenInfo(r.Context(), string(idBytes))
+	if err != nil || tokenInfo.Expiry.Before(time.Now()) {
+		return &IntrospectionResponse{Active: false}
+	}
+
+	resp := &IntrospectionResponse{
+		Active:    true,
+		TokenType: TokenTypeHintRefreshToken,
+		Exp:       tokenInfo.Expiry.Unix(),
+	}
+
+	// The details of the grant are taken from the access token issued with the refresh
+	// token, which has usually expired by now
+	if mapClaims, err := o.refreshTokenClaims(r.Context(), refreshToken); err == nil {
+		grant := introspectionFromClaims(mapClaims)
+		resp.Scope = grant.Scope
+		resp.ClientId = grant.ClientId
+		resp.Username = grant.Username
+		resp.Sub = grant.Sub
+		resp.Aud = grant.Aud
+		resp.Iss = grant.Iss
+	}
+	return resp
+}
+
+func introspectionFromClaims(mapClaims jwt.MapClaims) *IntrospectionResponse {
+	resp := &IntrospectionResponse{
+		Scope:    stringClaim(mapClaims, "scope"),
+		ClientId: stringClaim(mapClaims, "client_id"),
+		Sub:      stringClaim(mapClaims, "sub"),
+		Aud:      stringClaim(mapClaims, "aud"),
+		Iss:      stringClaim(mapClaims, "iss"),
+		Jti:      stringClaim(mapClaims, "jti"),
+		Exp:      numericClaim(mapClaims, "exp"),
+		Iat:      numericClaim(mapClaims, "iat"),
+		Nbf:      numericClaim(mapClaims, "nbf"),
+	}
+	// Tokens of the client credentials grant have the client as subject and no user
+	if resp.Sub != resp.ClientId {
+		resp.Username = resp.Sub
+	}
+	return resp
+}
+
+func stringClaim(mapClaims jwt.MapClaims, name string) string {
+	value, _ := mapClaims[name].(string)
+	return value
+}
+
+func numericClaim(mapClaims jwt.MapClaims, name string) int64 {
+	switch value := mapClaims[name].(type) {
+	case float64:
+		return int64(value)
+	case int64:
+		return value
+	case json.Number:
+		n, _ := value.Int64()
+		return n
+	}
+	return 0
+}
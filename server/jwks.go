@@ -0,0 +1,52 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Ashik80/oauth2jwtgen/jwk"
+	"github.com/Ashik80/oauth2jwtgen/manager"
+)
+
+// Serves the public keys of the key manager as a JSON Web Key Set. Managers of symmetric
+// keys have nothing to publish so the set is empty for them. Keys that can not be read are
+// left out and logged, so that the other keys can still be verified.
+func (o *OAuthServer) JWKS() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		set := o.keySet()
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(set)
+	}
+}
+
+func (o *OAuthServer) keySet() *jwk.Set {
+	set := &jwk.Set{Keys: []jwk.Key{}}
+
+	// Pending keys are listed so that clients have them before they sign tokens, and
+	// retiring keys until the last token they signed has expired
+	now := time.Now()
+	for _, kid := range o.kmanager.KeyIDs() {
+		info, err := o.kmanager.GetKeyInfo(kid)
+		if err != nil {
+			o.options.Logf("jwks: skipping key %s: %v", kid, err)
+			continue
+		}
+		if info.PublicKey == nil {
+			continue
+		}
+		if status, err := o.keyStatus(kid, now); err != nil || status == manager.KeyRetired {
+			continue
+		}
+		key, err := jwk.FromPublicKey(kid, info.Alg, info.PublicKey)
+		if err != nil {
+			o.options.Logf("jwks: skipping key %s: %v", kid, err)
+			continue
+		}
+		set.Keys = append(set.Keys, *key)
+	}
+	return set
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"time"
+
+	"github.com/Ashik80/oauth2jwtgen/accessor"
+	"github.com/Ashik80/oauth2jwtgen/manager"
+	"github.com/Ashik80/oauth2jwtgen/verifier"
+	"github.com/golang-jwt/jwt"
+)
+
+// Returns the accessor that signs new tokens
+func (o *OAuthServer) newAccess() (accessor.JWTAccess, error) {
+	kid, err := o.signingKid()
+	if err != nil {
+		return nil, err
+	}
+	return o.newAccessFor(kid)
+}
+
+func (o *OAuthServer) newAccessFor(kid string) (accessor.JWTAccess, error) {
+	return accessor.NewAccess(kid, o.kmanager)
+}
+
+// Returns the kid the server was created with, or the newest active key of the
+// manager when the server was created without a kid
+func (o *OAuthServer) signingKid() (string, error) {
+	if o.kid != "" {
+		return o.kid, nil
+	}
+	return manager.ActiveKey(o.kmanager, time.Now())
+}
+
+// Returns how long the key verifies tokens after it is retired when no expiry was set for it.
+// Refresh tokens are included because renewing one verifies the access token issued with it.
+func (o *OAuthServer) tokenLifetime() time.Duration {
+	lifetime := o.options.Validity.AccessExpiresIn
+	if refresh := int64(o.options.Validity.RefreshExpiresIn); refresh > lifetime {
+		lifetime = refresh
+	}
+	return time.Duration(lifetime) * time.Second
+}
+
+// Returns the status of the key. Retired keys without an expiry verify tokens for tokenLifetime.
+func (o *OAuthServer) keyStatus(kid string, now time.Time) (manager.KeyStatus, error) {
+	info, err := o.kmanager.GetKeyInfo(kid)
+	if err != nil {
+		return manager.KeyRetired, err
+	}
+	return info.Metadata.WithDefaultExpiry(o.tokenLifetime()).Status(now), nil
+}
+
+// Resolves the keys of the manager for the verifier of the server like verifier.ManagerKeys does.
+// Tokens without a kid header are resolved to the signing key.
+type serverKeys struct {
+	o *OAuthServer
+}
+
+func (k serverKeys) ResolveKey(kid string) (interface{}, string, error) {
+	if kid == "" {
+		signingKid, err := k.o.signingKid()
+		if err != nil {
+			return nil, "", err
+		}
+		kid = signingKid
+	}
+	keys := verifier.ManagerKeysWithOptions(k.o.kmanager, &verifier.ManagerKeysOptions{TokenLifetime: k.o.tokenLifetime()})
+	return keys.ResolveKey(kid)
+}
+
+// Returns the verifier of the access tokens issued by the server. Tokens revoked at the
+// revocation endpoint are rejected when a revoked token store is set.
+func (o *OAuthServer) tokenVerifier() *verifier.Verifier {
+	return verifier.NewKeyVerifier(serverKeys{o}, &verifier.VerifierOptions{
+		RevokedTokenStore: o.options.RevokedTokenStore,
+	})
+}
+
+// Resolves the key that verifies a token from its kid header, see tokenVerifier. The algorithm
+// of the token must be the one recorded for the key, and pending or retired keys are rejected.
+func (o *OAuthServer) keyfunc(token *jwt.Token) (interface{}, error) {
+	return o.tokenVerifier().Keyfunc(token)
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+
+	"github.com/Ashik80/oauth2jwtgen/accessor"
+	"github.com/Ashik80/oauth2jwtgen/store"
+	"github.com/golang-jwt/jwt"
+)
+
+// Handles the refresh_token grant of the token endpoint. The refresh token is read from
+// the form body or, when refresh tokens are stored in a cookie, from that cookie.
+// When a client store is configured the client is authenticated, and refresh tokens
+// issued to a client can only be used by that client.
+func (o *OAuthServer) RefreshToken() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		if err := r.ParseForm(); err != nil {
+			WriteError(w, http.StatusBadRequest, "invalid_request", err.Error())
+			return
+		}
+
+		if r.FormValue("grant_type") != GrantTypeRefreshToken {
+			WriteError(w, http.StatusBadRequest, "unsupported_grant_type", "")
+			return
+		}
+
+		refreshToken := r.FormValue("refresh_token")
+		if refreshToken == "" && o.options.IsRefreshTokenInCookie() {
+			if cookie, err := r.Cookie(o.options.GetRefreshCookieOptions().GetName()); err == nil {
+				refreshToken = cookie.Value
+			}
+		}
+		if refreshToken == "" {
+			WriteError(w, http.StatusBadRequest, "invalid_request", "refresh_token is required")
+			return
+		}
+
+		var client *store.Client
+		if o.options.ClientStore != nil {
+			authenticated, err := o.authenticateClient(ctx, r)
+			if err != nil {
+				WriteError(w, http.StatusUnauthorized, "invalid_client", err.Error())
+				return
+			}
+			client = authenticated
+		}
+		if mapClaims, err := o.refreshTokenClaims(ctx, refreshToken); err == nil && !canUseToken(mapClaims, client) {
+			WriteError(w, http.StatusBadRequest, "invalid_grant", "refresh token was issued to another client")
+			return
+		}
+
+		access, err := o.newAccess()
+		if err != nil {
+			WriteError(w, http.StatusInternalServerError, "server_error", err.Error())
+			return
+		}
+
+		token, err := accessor.RenewTokenWithKeyfunc(ctx, access, refreshToken, o.keyfunc, o.options)
+		if err != nil {
+			if errors.Is(err, accessor.ErrInvalidRefreshToken) {
+				WriteError(w, http.StatusBadRequest, "invalid_grant", err.Error())
+				return
+			}
+			WriteError(w, http.StatusInternalServerError, "server_error", err.Error())
+			return
+		}
+		token.RefreshToken = refreshToken
+
+		o.setTokenCookies(w, token)
+		writeToken(w, token)
+	}
+}
+
+// Returns the claims of the access token that was issued with the refresh token. The claims are
+// not verified because the token store only holds tokens that the server issued.
+func (o *OAuthServer) refreshTokenClaims(ctx context.Context, refreshToken string) (jwt.MapClaims, error) {
+	idBytes, err := base64.URLEncoding.DecodeString(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	tokenInfo, err := o.options.Store.GetTokenInfo(ctx, string(idBytes))
+	if err != nil {
+		return nil, err
+	}
+	mapClaims := jwt.MapClaims{}
+	if _, _, err := new(jwt.Parser).ParseUnverified(tokenInfo.AccessToken, mapClaims); err != nil {
+		return nil, err
+	}
+	return mapClaims, nil
+}
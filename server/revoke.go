@@ -0,0 +1,129 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Ashik80/oauth2jwtgen/store"
+	"github.com/golang-jwt/jwt"
+)
+
+var errUnsupportedTokenType = errors.New("access tokens can not be revoked without a revoked token store")
+
+// Handles token revocation requests as described in RFC 7009. Revoking a refresh token
+// removes it from the token store. Access tokens are added to the RevokedTokenStore
+// when one is configured. The client is authenticated when a client store is configured,
+// and tokens issued to another client are answered with 200 as well but are not revoked,
+// just like unknown tokens. Tokens without a client_id can be revoked by any client.
+func (o *OAuthServer) Revoke() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		if err := r.ParseForm(); err != nil {
+			WriteError(w, http.StatusBadRequest, "invalid_request", err.Error())
+			return
+		}
+
+		var client *store.Client
+		if o.options.ClientStore != nil {
+			authenticated, err := o.authenticateClient(ctx, r)
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Basic realm="revoke"`)
+				WriteError(w, http.StatusUnauthorized, "invalid_client", "client authentication failed")
+				return
+			}
+			client = authenticated
+		}
+
+		token := r.FormValue("token")
+		if token == "" {
+			WriteError(w, http.StatusBadRequest, "invalid_request", "token is required")
+			return
+		}
+
+		var revoked bool
+		var err error
+		if r.FormValue("token_type_hint") == TokenTypeHintAccessToken {
+			revoked, err = o.revokeAccessToken(ctx, client, token)
+			if err == nil && !revoked {
+				revoked, err = o.revokeRefreshToken(ctx, client, token)
+			}
+		} else {
+			revoked, err = o.revokeRefreshToken(ctx, client, token)
+			if err == nil && !revoked {
+				_, err = o.revokeAccessToken(ctx, client, token)
+			}
+		}
+
+		if errors.Is(err, errUnsupportedTokenType) {
+			WriteError(w, http.StatusBadRequest, "unsupported_token_type", err.Error())
+			return
+		}
+		if err != nil {
+			WriteError(w, http.StatusServiceUnavailable, "server_error", err.Error())
+			return
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// Returns false if the token is not a refresh token that the client may revoke
+func (o *OAuthServer) revokeRefreshToken(ctx context.Context, client *store.Client, refreshToken string) (bool, error) {
+	idBytes, err := base64.URLEncoding.DecodeString(refreshToken)
+	if err != nil {
+		return false, nil
+	}
+
+	// The access token issued with the refresh token tells which client it belongs to
+	mapClaims, err := o.refreshTokenClaims(ctx, refreshToken)
+	if err != nil {
+		return false, nil
+	}
+	if !canUseToken(mapClaims, client) {
+		return false, nil
+	}
+
+	if err := o.options.Store.DeleteTokenInfo(ctx, string(idBytes)); err != nil {
+		return false, err
+	}
+
+	if o.options.RevokedTokenStore != nil {
+		if err := o.revokeJti(ctx, mapClaims); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// Returns false if the token is not a valid access token that the client may revoke
+func (o *OAuthServer) revokeAccessToken(ctx context.Context, client *store.Client, accessToken string) (bool, error) {
+	mapClaims, err := o.tokenVerifier().Verify(accessToken)
+	if err != nil {
+		return false, nil
+	}
+	if !canUseToken(mapClaims, client) {
+		return false, nil
+	}
+
+	if o.options.RevokedTokenStore == nil {
+		return false, errUnsupportedTokenType
+	}
+	if err := o.revokeJti(ctx, mapClaims); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (o *OAuthServer) revokeJti(ctx context.Context, mapClaims jwt.MapClaims) error {
+	jti := stringClaim(mapClaims, "jti")
+	exp := numericClaim(mapClaims, "exp")
+	if jti == "" || exp < time.Now().Unix() {
+		return nil
+	}
+	return o.options.RevokedTokenStore.RevokeAccessToken(ctx, jti, time.Unix(exp, 0))
+}
@@ -4,17 +4,20 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sync"
 
-	"github.com/Ashik80/oauth2jwtgen/accessor"
-	"github.com/Ashik80/oauth2jwtgen/claims"
 	"github.com/Ashik80/oauth2jwtgen/manager"
 	"github.com/Ashik80/oauth2jwtgen/options"
+	"github.com/Ashik80/oauth2jwtgen/store"
 )
 
 type OAuthServer struct {
-	kid      string
-	kmanager manager.Manager
-	options  *options.AuthOptions
+	kid           string
+	kmanager      manager.Manager
+	options       *options.AuthOptions
+	grantTypes    []string
+	responseTypes []string
+	mu            sync.Mutex
 }
 
 type CallbackError struct {
@@ -32,6 +35,8 @@ func (c *CallbackError) Error() string {
 
 type AuthCallbackFunc func(r *http.Request, opt *options.AuthOptions) *CallbackError
 
+// Creates the server that signs tokens with the key kid. When kid is empty the newest active
+// key of the manager is used, so that keys can be rotated while the server is running.
 func NewOAuthServer(kid string, kmanager manager.Manager, opt *options.AuthOptions) (*OAuthServer, error) {
 	if opt.Store == nil {
 		return nil, fmt.Errorf("token store not specified")
@@ -44,6 +49,12 @@ func NewOAuthServer(kid string, kmanager manager.Manager, opt *options.AuthOptio
 	if opt.Validity.AccessExpiresIn == 0 {
 		opt.Validity.SetDefaultAccessExpiresIn()
 	}
+	if opt.Validity.CodeExpiresIn == 0 {
+		opt.Validity.SetDefaultCodeExpiresIn()
+	}
+	if opt.AuthCodeStore == nil {
+		opt.AuthCodeStore = new(store.MemoryAuthCodeStore)
+	}
 
 	if opt.Store == nil {
 		return nil, fmt.Errorf("token store not specified")
@@ -70,7 +81,7 @@ func (o *OAuthServer) ResourceOwnerPasswordCredential(
 
 		grantType := r.FormValue("grant_type")
 
-		if grantType != "password" {
+		if grantType != GrantTypePassword {
 			w.WriteHeader(http.StatusInternalServerError)
 			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
 			return
@@ -86,66 +97,26 @@ func (o *OAuthServer) ResourceOwnerPasswordCredential(
 		username := r.FormValue("username")
 		aud := r.Header.Get("Origin")
 
-		var access accessor.JWTAccess
-		var err error
-
-		if man, ok := o.kmanager.(*manager.HSKeyManager); ok {
-			access, err = accessor.NewHS256Access(o.kid, man)
-			if err != nil {
-				w.WriteHeader(http.StatusInternalServerError)
-				json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
-				return
-			}
-		} else if man, ok := o.kmanager.(*manager.RSKeyManager); ok {
-			access, err = accessor.NewRS256Access(o.kid, man)
-			if err != nil {
-				w.WriteHeader(http.StatusInternalServerError)
-				json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
-				return
-			}
-		} else {
+		access, err := o.newAccess()
+		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(map[string]string{"error": "invalid key manager"})
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
 			return
 		}
 
-		issuer := r.Host
-
 		var scope string
-		var roles []string
 		if o.options.IsAccessTokenClaimsSet(username) {
-			setClaims := o.options.GetAccessTokenClaims(username)
-			scope = setClaims.Scope
-			roles = setClaims.Roles
-		}
-
-		accessClaims := claims.GenerateAccessClaims(username, issuer, aud, scope, roles, o.options.Validity.AccessExpiresIn)
-		c := &claims.JWTClaims{
-			AccessClaims: accessClaims,
+			scope = o.options.GetAccessTokenClaims(username).Scope
 		}
 
-		if o.options.IsIdTokenClaimsSet(username) {
-			c.IdClaims = o.options.GetIdTokenClaims(username)
-			c.IdClaims.MapClaims(accessClaims)
-		}
-
-		token, err := accessor.NewToken(ctx, access, c, o.options)
-
+		token, err := o.newToken(ctx, access, username, o.issuer(r), aud, "", scope, "")
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
 			json.NewEncoder(w).Encode(map[string]string{"message": err.Error()})
 			return
 		}
 
-		if o.options.IsRefreshTokenInCookie() {
-			refreshCookie := SetCookie(o.options.GetRefreshCookieOptions(), token.RefreshToken)
-			http.SetCookie(w, refreshCookie)
-		}
-
-		if o.options.IsAccessTokenInCookie() {
-			accessCookie := SetCookie(o.options.GetAccessCookieOptions(), token.AccessToken)
-			http.SetCookie(w, accessCookie)
-		}
+		o.setTokenCookies(w, token)
 
 		w.WriteHeader(http.StatusOK)
 		json.NewEncoder(w).Encode(token)
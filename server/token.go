@@ -0,0 +1,114 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/Ashik80/oauth2jwtgen/accessor"
+	"github.com/Ashik80/oauth2jwtgen/claims"
+)
+
+const (
+	GrantTypePassword          = "password"
+	GrantTypeAuthorizationCode = "authorization_code"
+	GrantTypeClientCredentials = "client_credentials"
+	GrantTypeRefreshToken      = "refresh_token"
+)
+
+// Token dispatches a token endpoint request to the handler registered for its grant_type
+func (o *OAuthServer) Token(grants map[string]http.HandlerFunc) http.HandlerFunc {
+	o.mu.Lock()
+	for grantType := range grants {
+		o.grantTypes = appendUnique(o.grantTypes, grantType)
+	}
+	o.mu.Unlock()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			WriteError(w, http.StatusBadRequest, "invalid_request", err.Error())
+			return
+		}
+
+		grantType := r.FormValue("grant_type")
+		if grantType == "" {
+			WriteError(w, http.StatusBadRequest, "invalid_request", "grant_type is required")
+			return
+		}
+
+		handler, ok := grants[grantType]
+		if !ok {
+			WriteError(w, http.StatusBadRequest, "unsupported_grant_type", "")
+			return
+		}
+
+		handler(w, r)
+	}
+}
+
+// Writes an error response as described in RFC 6749 section 5.2
+func WriteError(w http.ResponseWriter, statusCode int, code string, description string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(statusCode)
+
+	body := map[string]string{"error": code}
+	if description != "" {
+		body["error_description"] = description
+	}
+	json.NewEncoder(w).Encode(body)
+}
+
+func (o *OAuthServer) issuer(r *http.Request) string {
+	if o.options.Issuer != "" {
+		return o.options.Issuer
+	}
+	return r.Host
+}
+
+// Builds the access and id claims for the resource owner and returns the signed tokens.
+// The id token is only issued when IsIdTokenAllowed allows it.
+func (o *OAuthServer) newToken(ctx context.Context, access accessor.JWTAccess, username, issuer, aud, clientId, scope, nonce string) (*accessor.Token, error) {
+	var roles []string
+	if o.options.IsAccessTokenClaimsSet(username) {
+		roles = o.options.GetAccessTokenClaims(username).Roles
+	}
+
+	accessClaims := claims.GenerateAccessClaims(username, issuer, aud, scope, roles, o.options.Validity.AccessExpiresIn)
+	accessClaims.ClientId = clientId
+	c := &claims.JWTClaims{
+		AccessClaims: accessClaims,
+	}
+
+	if o.options.IsIdTokenAllowed(scope, clientId) && o.options.IsIdTokenClaimsSet(username) {
+		c.IdClaims = o.options.GetIdTokenClaims(username)
+		c.IdClaims.MapClaims(accessClaims)
+		if nonce != "" {
+			// The nonce belongs to this request only so it is not set on the shared claims
+			idClaims := *c.IdClaims
+			idClaims.Nonce = nonce
+			c.IdClaims = &idClaims
+		}
+	}
+
+	return accessor.NewToken(ctx, access, c, o.options)
+}
+
+func (o *OAuthServer) setTokenCookies(w http.ResponseWriter, token *accessor.Token) {
+	if o.options.IsRefreshTokenInCookie() && token.RefreshToken != "" {
+		refreshCookie := SetCookie(o.options.GetRefreshCookieOptions(), token.RefreshToken)
+		http.SetCookie(w, refreshCookie)
+	}
+
+	if o.options.IsAccessTokenInCookie() {
+		accessCookie := SetCookie(o.options.GetAccessCookieOptions(), token.AccessToken)
+		http.SetCookie(w, accessCookie)
+	}
+}
+
+func writeToken(w http.ResponseWriter, token *accessor.Token) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(token)
+}
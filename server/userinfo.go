@@ -0,0 +1,160 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/Ashik80/oauth2jwtgen/accessor"
+	"github.com/Ashik80/oauth2jwtgen/claims"
+	"github.com/Ashik80/oauth2jwtgen/verifier"
+	"github.com/golang-jwt/jwt"
+)
+
+// Returns the id token claims of the subject of an access token
+type UserInfoFunc func(ctx context.Context, sub string) (*claims.JWTIdClaims, error)
+
+// Claims released for each scope as described in OpenID Connect Core 1.0 section 5.4
+var scopeClaims = map[string][]string{
+	"profile": {"name", "given_name", "family_name", "picture", "locale", "preferred_username"},
+	"email":   {"email", "email_verified"},
+}
+
+// Handles UserInfo requests. The claims come from f, or from the id token claims set in the
+// options when f is nil, and only the claims of the scopes granted to the token are returned.
+// Clients that send "Accept: application/jwt" get the claims as a signed JWT.
+func (o *OAuthServer) UserInfo(f UserInfoFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		tokenString := bearerToken(r)
+		if tokenString == "" {
+			verifier.WriteBearerError(w, "userinfo", &verifier.BearerError{StatusCode: http.StatusUnauthorized})
+			return
+		}
+
+		mapClaims, err := o.tokenVerifier().Verify(tokenString)
+		if errors.Is(err, verifier.ErrRevoked) {
+			writeBearerError(w, http.StatusUnauthorized, "invalid_token", "the access token was revoked")
+			return
+		}
+		if err != nil {
+			writeBearerError(w, http.StatusUnauthorized, "invalid_token", "the access token is invalid")
+			return
+		}
+
+		scopes := strings.Fields(stringClaim(mapClaims, "scope"))
+		if !slices.Contains(scopes, "openid") {
+			writeBearerError(w, http.StatusForbidden, "insufficient_scope", "the openid scope is required")
+			return
+		}
+
+		sub := stringClaim(mapClaims, "sub")
+		idClaims, err := o.userInfoClaims(ctx, f, sub)
+		if err != nil {
+			WriteError(w, http.StatusInternalServerError, "server_error", err.Error())
+			return
+		}
+
+		info, err := filterUserInfo(idClaims, scopes)
+		if err != nil {
+			WriteError(w, http.StatusInternalServerError, "server_error", err.Error())
+			return
+		}
+		info["sub"] = sub
+
+		w.Header().Set("Cache-Control", "no-store")
+
+		if !strings.Contains(r.Header.Get("Accept"), "application/jwt") {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(info)
+			return
+		}
+
+		info["iss"] = o.issuer(r)
+		if clientId := stringClaim(mapClaims, "client_id"); clientId != "" {
+			info["aud"] = clientId
+		}
+		signed, err := o.signClaims(info)
+		if err != nil {
+			WriteError(w, http.StatusInternalServerError, "server_error", err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "application/jwt")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(signed))
+	}
+}
+
+func (o *OAuthServer) userInfoClaims(ctx context.Context, f UserInfoFunc, sub string) (*claims.JWTIdClaims, error) {
+	if f != nil {
+		return f(ctx, sub)
+	}
+	if !o.options.IsIdTokenClaimsSet(sub) {
+		return &claims.JWTIdClaims{}, nil
+	}
+	return o.options.GetIdTokenClaims(sub), nil
+}
+
+func (o *OAuthServer) signClaims(c jwt.MapClaims) (string, error) {
+	access, err := o.newAccess()
+	if err != nil {
+		return "", err
+	}
+	key, err := accessor.GetParsedSigningKey(access)
+	if err != nil {
+		return "", err
+	}
+	return accessor.GenerateTokenString(access, c, key)
+}
+
+// Returns the claims that belong to the granted scopes
+func filterUserInfo(idClaims *claims.JWTIdClaims, scopes []string) (jwt.MapClaims, error) {
+	b, err := json.Marshal(idClaims)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode claims: %w", err)
+	}
+	all := make(map[string]interface{})
+	if err := json.Unmarshal(b, &all); err != nil {
+		return nil, fmt.Errorf("failed to decode claims: %w", err)
+	}
+
+	info := jwt.MapClaims{}
+	for _, scope := range scopes {
+		for _, name := range scopeClaims[scope] {
+			if value, ok := all[name]; ok {
+				info[name] = value
+			}
+		}
+	}
+	return info, nil
+}
+
+// Returns the token of the Authorization header or of the access_token form value
+func bearerToken(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		scheme, token, found := strings.Cut(auth, " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") {
+			return ""
+		}
+		return strings.TrimSpace(token)
+	}
+	if r.Method == http.MethodPost {
+		return r.PostFormValue("access_token")
+	}
+	return ""
+}
+
+// Writes an error as described in RFC 6750 section 3
+func writeBearerError(w http.ResponseWriter, statusCode int, code string, description string) {
+	verifier.WriteBearerError(w, "userinfo", &verifier.BearerError{
+		StatusCode:  statusCode,
+		Code:        code,
+		Description: description,
+	})
+}
@@ -0,0 +1,24 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+type AuthCodeStore interface {
+	StoreAuthCode(ctx context.Context, authCode *AuthCode) error
+	// ConsumeAuthCode returns the code and removes it so that it can only be used once
+	ConsumeAuthCode(ctx context.Context, code string) (*AuthCode, error)
+}
+
+type AuthCode struct {
+	Code                string
+	ClientId            string
+	RedirectUri         string
+	ResourceOwnerId     string
+	Scope               string
+	Nonce               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+	Expiry              time.Time
+}
@@ -0,0 +1,34 @@
+package store
+
+import "context"
+
+type ClientStore interface {
+	GetClient(ctx context.Context, clientId string) (*Client, error)
+}
+
+type Client struct {
+	Id           string
+	Secret       string
+	RedirectUris []string
+	Scopes       []string
+	// Public clients (browser and mobile apps) have no secret and must use PKCE
+	Public bool
+}
+
+func (c *Client) HasRedirectUri(redirectUri string) bool {
+	for _, uri := range c.RedirectUris {
+		if uri == redirectUri {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Client) HasScope(scope string) bool {
+	for _, s := range c.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
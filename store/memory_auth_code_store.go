@@ -0,0 +1,34 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+type MemoryAuthCodeStore struct {
+	AuthCodes map[string]AuthCode
+	mu        sync.Mutex
+}
+
+func (s *MemoryAuthCodeStore) StoreAuthCode(ctx context.Context, authCode *AuthCode) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.AuthCodes == nil {
+		s.AuthCodes = make(map[string]AuthCode)
+	}
+	s.AuthCodes[authCode.Code] = *authCode
+
+	return nil
+}
+
+func (s *MemoryAuthCodeStore) ConsumeAuthCode(ctx context.Context, code string) (*AuthCode, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	authCode, exists := s.AuthCodes[code]
+	if !exists {
+		return nil, errors.New("authorization code not found")
+	}
+	delete(s.AuthCodes, code)
+	return &authCode, nil
+}
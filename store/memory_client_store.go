@@ -0,0 +1,31 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+type MemoryClientStore struct {
+	Clients map[string]Client
+	mu      sync.Mutex
+}
+
+func (s *MemoryClientStore) AddClient(client *Client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Clients == nil {
+		s.Clients = make(map[string]Client)
+	}
+	s.Clients[client.Id] = *client
+}
+
+func (s *MemoryClientStore) GetClient(ctx context.Context, clientId string) (*Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	client, exists := s.Clients[clientId]
+	if !exists {
+		return nil, errors.New("client not found")
+	}
+	return &client, nil
+}
@@ -0,0 +1,38 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type MemoryRevokedTokenStore struct {
+	RevokedTokens map[string]time.Time
+	mu            sync.Mutex
+}
+
+func (s *MemoryRevokedTokenStore) RevokeAccessToken(ctx context.Context, jti string, expiry time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.RevokedTokens == nil {
+		s.RevokedTokens = make(map[string]time.Time)
+	}
+
+	// Tokens that have expired are rejected anyway so they don't need to be kept
+	now := time.Now()
+	for id, exp := range s.RevokedTokens {
+		if exp.Before(now) {
+			delete(s.RevokedTokens, id)
+		}
+	}
+	s.RevokedTokens[jti] = expiry
+
+	return nil
+}
+
+func (s *MemoryRevokedTokenStore) IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, revoked := s.RevokedTokens[jti]
+	return revoked, nil
+}
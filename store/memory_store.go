@@ -51,6 +51,14 @@ func (s *MemoryTokenStore) UpdateTokenInfo(ctx context.Context, resourceOwnerId
 	return nil
 }
 
+func (s *MemoryTokenStore) DeleteTokenInfo(ctx context.Context, resourceOwnerId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.TokenInfos, resourceOwnerId)
+
+	return nil
+}
+
 func (s *MemoryTokenStore) CloseConnection() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -0,0 +1,12 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// Keeps the ids (jti) of access tokens that were revoked before they expired
+type RevokedTokenStore interface {
+	RevokeAccessToken(ctx context.Context, jti string, expiry time.Time) error
+	IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error)
+}
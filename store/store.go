@@ -10,6 +10,7 @@ type TokenStore interface {
 	StoreToken(ctx context.Context, tokenInfo *TokenInfo) error
 	GetTokenInfo(ctx context.Context, resourceOwnerId string) (*TokenInfo, error)
 	UpdateTokenInfo(ctx context.Context, resourceOwnerId string, accessToken string, idToken string) error
+	DeleteTokenInfo(ctx context.Context, resourceOwnerId string) error
 	CloseConnection() error
 }
 
@@ -0,0 +1,49 @@
+package verifier
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+
+	"github.com/golang-jwt/jwt"
+)
+
+func LoadECPublicKeyFromFile(filePath string) (*ecdsa.PublicKey, error) {
+	pubKey, format, err := loadPublicKeyFromFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	ecPubKey, ok := pubKey.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%s key is not an ECDSA public key", format)
+	}
+
+	return ecPubKey, nil
+}
+
+func VerifyESToken(tokenString string, filePath string) (jwt.MapClaims, error) {
+	publicKey, err := LoadECPublicKeyFromFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	token, err := ParseESToken(tokenString, publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing token: %w", err)
+	}
+
+	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
+		return claims, nil
+	} else {
+		return nil, fmt.Errorf("invalid token or claim")
+	}
+}
+
+func ParseESToken(tokenString string, signingKey *ecdsa.PublicKey) (*jwt.Token, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodECDSA); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return signingKey, nil
+	})
+	return token, err
+}
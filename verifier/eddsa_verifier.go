@@ -0,0 +1,49 @@
+package verifier
+
+import (
+	"crypto/ed25519"
+	"fmt"
+
+	"github.com/golang-jwt/jwt"
+)
+
+func LoadEdPublicKeyFromFile(filePath string) (ed25519.PublicKey, error) {
+	pubKey, format, err := loadPublicKeyFromFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	edPubKey, ok := pubKey.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%s key is not an Ed25519 public key", format)
+	}
+
+	return edPubKey, nil
+}
+
+func VerifyEdToken(tokenString string, filePath string) (jwt.MapClaims, error) {
+	publicKey, err := LoadEdPublicKeyFromFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	token, err := ParseEdToken(tokenString, publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing token: %w", err)
+	}
+
+	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
+		return claims, nil
+	} else {
+		return nil, fmt.Errorf("invalid token or claim")
+	}
+}
+
+func ParseEdToken(tokenString string, signingKey ed25519.PublicKey) (*jwt.Token, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodEd25519); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return signingKey, nil
+	})
+	return token, err
+}
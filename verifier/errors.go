@@ -0,0 +1,18 @@
+package verifier
+
+import "errors"
+
+// Errors returned by Verifier. They are wrapped with details, so compare them with errors.Is.
+var (
+	ErrMalformed           = errors.New("token is malformed")
+	ErrBadSignature        = errors.New("token signature is invalid")
+	ErrAlgorithmNotAllowed = errors.New("token signing algorithm is not allowed")
+	ErrUnknownKey          = errors.New("token signing key is unknown")
+	ErrExpired             = errors.New("token is expired")
+	ErrNotYetValid         = errors.New("token is not valid yet")
+	ErrTooOld              = errors.New("token is too old")
+	ErrWrongIssuer         = errors.New("token issuer is not accepted")
+	ErrWrongAudience       = errors.New("token audience is not accepted")
+	ErrMissingClaim        = errors.New("token claim is missing")
+	ErrRevoked             = errors.New("token was revoked")
+)
@@ -0,0 +1,118 @@
+package verifier
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/Ashik80/oauth2jwtgen/claims"
+)
+
+// Reports whether the claims of a request grant access to a resource
+type Predicate func(r *http.Request, c *claims.JWTAccessClaims) bool
+
+// Granted when the token has every one of the scopes
+func HasScopes(scopes ...string) Predicate {
+	return func(r *http.Request, c *claims.JWTAccessClaims) bool {
+		granted := strings.Fields(c.Scope)
+		for _, scope := range scopes {
+			if !slices.Contains(granted, scope) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Granted when the token has at least one of the roles
+func HasAnyRole(roles ...string) Predicate {
+	return func(r *http.Request, c *claims.JWTAccessClaims) bool {
+		for _, role := range roles {
+			if slices.Contains(c.Roles, role) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Granted when the token has every one of the roles
+func HasAllRoles(roles ...string) Predicate {
+	return func(r *http.Request, c *claims.JWTAccessClaims) bool {
+		for _, role := range roles {
+			if !slices.Contains(c.Roles, role) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Granted when one of the predicates grants access
+func AnyOf(predicates ...Predicate) Predicate {
+	return func(r *http.Request, c *claims.JWTAccessClaims) bool {
+		for _, predicate := range predicates {
+			if predicate(r, c) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Passes on the requests whose claims satisfy the predicate and answers the others with
+// 403 insufficient_scope. It wraps handlers that are already wrapped by Middleware.
+func Require(predicate Predicate) func(http.Handler) http.Handler {
+	return guard(predicate, &BearerError{
+		StatusCode:  http.StatusForbidden,
+		Code:        "insufficient_scope",
+		Description: "the access token does not grant access to this resource",
+	})
+}
+
+// Requires every one of the scopes. They are listed in the WWW-Authenticate header when one is missing.
+func RequireScopes(scopes ...string) func(http.Handler) http.Handler {
+	return guard(HasScopes(scopes...), &BearerError{
+		StatusCode:  http.StatusForbidden,
+		Code:        "insufficient_scope",
+		Description: fmt.Sprintf("the scopes %s are required", strings.Join(scopes, ", ")),
+		Scope:       strings.Join(scopes, " "),
+	})
+}
+
+// Requires at least one of the roles
+func RequireAnyRole(roles ...string) func(http.Handler) http.Handler {
+	return guard(HasAnyRole(roles...), &BearerError{
+		StatusCode:  http.StatusForbidden,
+		Code:        "insufficient_scope",
+		Description: fmt.Sprintf("one of the roles %s is required", strings.Join(roles, ", ")),
+	})
+}
+
+// Requires every one of the roles
+func RequireAllRoles(roles ...string) func(http.Handler) http.Handler {
+	return guard(HasAllRoles(roles...), &BearerError{
+		StatusCode:  http.StatusForbidden,
+		Code:        "insufficient_scope",
+		Description: fmt.Sprintf("the roles %s are required", strings.Join(roles, ", ")),
+	})
+}
+
+func guard(predicate Predicate, denied *BearerError) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			realm, _ := r.Context().Value(realmKey).(string)
+			accessClaims, ok := AccessClaimsFromContext(r.Context())
+			if !ok {
+				WriteBearerError(w, realm, &BearerError{StatusCode: http.StatusUnauthorized})
+				return
+			}
+			if !predicate(r, accessClaims) {
+				WriteBearerError(w, realm, denied)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package verifier
+
+import (
+	"crypto"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Ashik80/oauth2jwtgen/keyutil"
+)
+
+// Public keys loaded from files. A file is read and parsed again when its modification
+// time or size changes, so that a token can be verified without parsing the key every time.
+var publicKeyCache = struct {
+	entries map[string]cachedPublicKey
+	mu      sync.Mutex
+}{entries: make(map[string]cachedPublicKey)}
+
+type cachedPublicKey struct {
+	modTime time.Time
+	size    int64
+	key     crypto.PublicKey
+	format  keyutil.Format
+}
+
+// Returns the public key of the file and the format it was read from
+func loadPublicKeyFromFile(filePath string) (crypto.PublicKey, keyutil.Format, error) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read file: %v", err)
+	}
+
+	publicKeyCache.mu.Lock()
+	defer publicKeyCache.mu.Unlock()
+	if entry, ok := publicKeyCache.entries[filePath]; ok &&
+		entry.modTime.Equal(info.ModTime()) && entry.size == info.Size() {
+		return entry.key, entry.format, nil
+	}
+
+	pubKeyFile, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read file: %v", err)
+	}
+
+	pubKey, format, err := keyutil.ParsePublicKey(pubKeyFile)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse public key: %w", err)
+	}
+
+	publicKeyCache.entries[filePath] = cachedPublicKey{
+		modTime: info.ModTime(),
+		size:    info.Size(),
+		key:     pubKey,
+		format:  format,
+	}
+	return pubKey, format, nil
+}
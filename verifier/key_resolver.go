@@ -0,0 +1,110 @@
+package verifier
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Ashik80/oauth2jwtgen/jwk"
+	"github.com/Ashik80/oauth2jwtgen/manager"
+)
+
+// Resolves the key that verifies a token from the kid in its header
+type KeyResolver interface {
+	// Returns the verification key and the algorithm it is pinned to. The algorithm is empty when
+	// every algorithm that fits the key is allowed. Unknown kids return an error wrapping ErrUnknownKey.
+	ResolveKey(kid string) (key interface{}, alg string, err error)
+}
+
+// Resolves every kid to the same key
+type staticKey struct {
+	key interface{}
+}
+
+func (k staticKey) ResolveKey(kid string) (interface{}, string, error) {
+	return k.key, "", nil
+}
+
+type managerResolver struct {
+	m             manager.Manager
+	tokenLifetime time.Duration
+}
+
+type ManagerKeysOptions struct {
+	// How long a key retired without an expiry keeps verifying tokens, the tokenLifetime passed to
+	// Rotate. Such keys verify tokens until they are removed when it is zero.
+	TokenLifetime time.Duration
+}
+
+// Resolves keys from a key manager. Each key is pinned to the signing method it was added with.
+// Only active and retiring keys are resolved, like the server does: pending keys have not signed
+// any token yet and retired keys are unknown just like they are missing from the JWKS endpoint.
+func ManagerKeys(m manager.Manager) KeyResolver {
+	return ManagerKeysWithOptions(m, nil)
+}
+
+// Same as ManagerKeys, with the lifetime of the keys retired without an expiry set by the options
+func ManagerKeysWithOptions(m manager.Manager, opt *ManagerKeysOptions) KeyResolver {
+	r := &managerResolver{m: m}
+	if opt != nil {
+		r.tokenLifetime = opt.TokenLifetime
+	}
+	return r
+}
+
+func (r *managerResolver) ResolveKey(kid string) (interface{}, string, error) {
+	if !slices.Contains(r.m.KeyIDs(), kid) {
+		return nil, "", fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+	}
+	info, err := r.m.GetKeyInfo(kid)
+	if err != nil {
+		return nil, "", err
+	}
+	md := info.Metadata
+	if r.tokenLifetime > 0 {
+		md = md.WithDefaultExpiry(r.tokenLifetime)
+	}
+	if status := md.Status(time.Now()); status != manager.KeyActive && status != manager.KeyRetiring {
+		return nil, "", fmt.Errorf("%w: %s is %s", ErrUnknownKey, kid, status)
+	}
+	if info.PublicKey != nil {
+		return info.PublicKey, info.Alg, nil
+	}
+	if info.Type != manager.KeyTypeOct {
+		return nil, "", fmt.Errorf("key %s has no public key", kid)
+	}
+	secret, err := r.m.GetSigningKey(kid)
+	if err != nil {
+		return nil, "", err
+	}
+	return secret, info.Alg, nil
+}
+
+type setResolver struct {
+	set *jwk.Set
+}
+
+// Resolves keys from a JWK set, such as the one served by the JWKS endpoint. Keys that
+// have an alg parameter are pinned to it.
+func SetKeys(set *jwk.Set) KeyResolver {
+	return &setResolver{set: set}
+}
+
+func (r *setResolver) ResolveKey(kid string) (interface{}, string, error) {
+	return resolveFromSet(r.set, kid)
+}
+
+func resolveFromSet(set *jwk.Set, kid string) (interface{}, string, error) {
+	k, ok := set.Key(kid)
+	if !ok || kid == "" {
+		return nil, "", fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+	}
+	if k.Use != "" && k.Use != "sig" {
+		return nil, "", fmt.Errorf("%w: %s is not a signing key", ErrUnknownKey, kid)
+	}
+	publicKey, err := k.PublicKey()
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid key %s: %w", kid, err)
+	}
+	return publicKey, k.Alg, nil
+}
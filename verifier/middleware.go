@@ -0,0 +1,273 @@
+package verifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Ashik80/oauth2jwtgen/claims"
+	"github.com/Ashik80/oauth2jwtgen/options"
+	"github.com/golang-jwt/jwt"
+)
+
+// Verifies a token and returns its claims. Verifier.Verify is one, and the Verify functions of this
+// package can be wrapped in one:
+//
+//	func(tokenString string) (jwt.MapClaims, error) { return verifier.VerifyHSToken(tokenString, secret) }
+type VerifyFunc func(tokenString string) (jwt.MapClaims, error)
+
+type MiddlewareOptions struct {
+	// Sent in the WWW-Authenticate header of error responses
+	Realm string
+	// The access token is read from this cookie when the request has no Authorization header,
+	// usually AuthOptions.GetAccessCookieOptions(). Nil reads the header only.
+	Cookie *options.CookieOptions
+}
+
+// Error of a request to a resource protected by a bearer token, as described in RFC 6750 section 3.
+// Code is empty when the request had no token.
+type BearerError struct {
+	StatusCode  int
+	Code        string
+	Description string
+	// Scope needed to access the resource
+	Scope string
+}
+
+func (e *BearerError) Error() string {
+	if e.Description == "" {
+		return e.Code
+	}
+	return e.Code + ": " + e.Description
+}
+
+type contextKey int
+
+const (
+	accessClaimsKey contextKey = iota
+	mapClaimsKey
+	realmKey
+)
+
+// Verifies the bearer token of every request and passes the request on with the claims of the token
+// in its context. Requests without a valid token are answered with 401 and a WWW-Authenticate header.
+func Middleware(verify VerifyFunc, opt *MiddlewareOptions) func(http.Handler) http.Handler {
+	if opt == nil {
+		opt = &MiddlewareOptions{}
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tokenString, bearerErr := requestToken(r, opt.Cookie)
+			if bearerErr != nil {
+				WriteBearerError(w, opt.Realm, bearerErr)
+				return
+			}
+
+			mapClaims, err := verify(tokenString)
+			if err != nil {
+				WriteBearerError(w, opt.Realm, invalidTokenError(err))
+				return
+			}
+			accessClaims, err := toAccessClaims(mapClaims)
+			if err != nil {
+				WriteBearerError(w, opt.Realm, &BearerError{
+					StatusCode:  http.StatusUnauthorized,
+					Code:        "invalid_token",
+					Description: "the access token claims are invalid",
+				})
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), accessClaimsKey, accessClaims)
+			ctx = context.WithValue(ctx, mapClaimsKey, mapClaims)
+			ctx = context.WithValue(ctx, realmKey, opt.Realm)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Returns the access claims that Middleware put in the context. When the token has several
+// audiences the Audience field holds the first one.
+func AccessClaimsFromContext(ctx context.Context) (*claims.JWTAccessClaims, bool) {
+	c, ok := ctx.Value(accessClaimsKey).(*claims.JWTAccessClaims)
+	return c, ok
+}
+
+// Returns all claims of the token that Middleware put in the context, including the custom ones
+func MapClaimsFromContext(ctx context.Context) (jwt.MapClaims, bool) {
+	c, ok := ctx.Value(mapClaimsKey).(jwt.MapClaims)
+	return c, ok
+}
+
+// Writes the error with its WWW-Authenticate header and a JSON body
+func WriteBearerError(w http.ResponseWriter, realm string, e *BearerError) {
+	params := []string{}
+	if realm != "" {
+		params = append(params, authParam("realm", realm))
+	}
+	if e.Code != "" {
+		params = append(params, authParam("error", e.Code))
+	}
+	if e.Description != "" {
+		params = append(params, authParam("error_description", e.Description))
+	}
+	if e.Scope != "" {
+		params = append(params, authParam("scope", e.Scope))
+	}
+	challenge := "Bearer"
+	if len(params) > 0 {
+		challenge += " " + strings.Join(params, ", ")
+	}
+	w.Header().Set("WWW-Authenticate", challenge)
+
+	if e.Code == "" {
+		w.WriteHeader(e.StatusCode)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(e.StatusCode)
+	body := map[string]string{"error": e.Code}
+	if e.Description != "" {
+		body["error_description"] = e.Description
+	}
+	json.NewEncoder(w).Encode(body)
+}
+
+// Reads the token from the Authorization header, or from the cookie when there is no header
+func requestToken(r *http.Request, cookie *options.CookieOptions) (string, *BearerError) {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		scheme, token, _ := strings.Cut(auth, " ")
+		if !strings.EqualFold(scheme, "Bearer") {
+			return "", &BearerError{StatusCode: http.StatusUnauthorized}
+		}
+		token = strings.TrimSpace(token)
+		if token == "" {
+			return "", &BearerError{
+				StatusCode:  http.StatusBadRequest,
+				Code:        "invalid_request",
+				Description: "the bearer token is empty",
+			}
+		}
+		return token, nil
+	}
+
+	if cookie != nil {
+		if c, err := r.Cookie(cookie.GetName()); err == nil && c.Value != "" {
+			return c.Value, nil
+		}
+	}
+	return "", &BearerError{StatusCode: http.StatusUnauthorized}
+}
+
+func invalidTokenError(err error) *BearerError {
+	description := "the access token is invalid"
+	var vErr *jwt.ValidationError
+	if errors.Is(err, ErrExpired) || errors.As(err, &vErr) && vErr.Errors&jwt.ValidationErrorExpired != 0 {
+		description = "the access token expired"
+	}
+	if errors.Is(err, ErrRevoked) {
+		description = "the access token was revoked"
+	}
+	return &BearerError{
+		StatusCode:  http.StatusUnauthorized,
+		Code:        "invalid_token",
+		Description: description,
+	}
+}
+
+// Converts the claims to access claims. The StandardClaims of jwt only hold one audience, so an
+// aud array is converted to its first value. MapClaimsFromContext returns all of them. The claims
+// are read one by one, so that fractional NumericDates are truncated instead of failing.
+func toAccessClaims(mapClaims jwt.MapClaims) (*claims.JWTAccessClaims, error) {
+	accessClaims := new(claims.JWTAccessClaims)
+
+	stringClaims := []struct {
+		name  string
+		value *string
+	}{
+		{"iss", &accessClaims.Issuer},
+		{"sub", &accessClaims.Subject},
+		{"jti", &accessClaims.Id},
+		{"client_id", &accessClaims.ClientId},
+		{"scope", &accessClaims.Scope},
+	}
+	for _, c := range stringClaims {
+		value, err := stringClaim(mapClaims, c.name)
+		if err != nil {
+			return nil, err
+		}
+		*c.value = value
+	}
+
+	timeClaims := []struct {
+		name  string
+		value *int64
+	}{
+		{"exp", &accessClaims.ExpiresAt},
+		{"iat", &accessClaims.IssuedAt},
+		{"nbf", &accessClaims.NotBefore},
+	}
+	for _, c := range timeClaims {
+		t, ok, err := timeClaim(mapClaims, c.name)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			*c.value = t.Unix()
+		}
+	}
+
+	switch aud := mapClaims["aud"].(type) {
+	case nil:
+	case string:
+		accessClaims.Audience = aud
+	case []interface{}:
+		if len(aud) > 0 {
+			first, ok := aud[0].(string)
+			if !ok {
+				return nil, fmt.Errorf("%w: aud is not a string or an array of strings", ErrMalformed)
+			}
+			accessClaims.Audience = first
+		}
+	default:
+		return nil, fmt.Errorf("%w: aud is not a string or an array of strings", ErrMalformed)
+	}
+
+	switch roles := mapClaims["roles"].(type) {
+	case nil:
+	case []interface{}:
+		accessClaims.Roles = make([]string, 0, len(roles))
+		for _, role := range roles {
+			s, ok := role.(string)
+			if !ok {
+				return nil, fmt.Errorf("%w: roles is not an array of strings", ErrMalformed)
+			}
+			accessClaims.Roles = append(accessClaims.Roles, s)
+		}
+	default:
+		return nil, fmt.Errorf("%w: roles is not an array of strings", ErrMalformed)
+	}
+	return accessClaims, nil
+}
+
+// Returns the claim when it is a string, and an empty string when it is missing
+func stringClaim(mapClaims jwt.MapClaims, name string) (string, error) {
+	switch value := mapClaims[name].(type) {
+	case nil:
+		return "", nil
+	case string:
+		return value, nil
+	}
+	return "", fmt.Errorf("%w: %s is not a string", ErrMalformed, name)
+}
+
+// Formats an auth-param of RFC 7235 as a quoted string
+func authParam(name, value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	return fmt.Sprintf(`%s="%s"`, name, value)
+}
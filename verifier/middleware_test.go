@@ -0,0 +1,88 @@
+package verifier
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/Ashik80/oauth2jwtgen/claims"
+	"github.com/golang-jwt/jwt"
+)
+
+func TestMiddlewareAccessClaims(t *testing.T) {
+	tests := []struct {
+		name      string
+		mapClaims jwt.MapClaims
+		want      *claims.JWTAccessClaims
+	}{
+		{
+			"fractional dates",
+			jwt.MapClaims{"sub": "user", "exp": 1700000000.5, "iat": 1699999000.25, "nbf": 1699999000.75},
+			&claims.JWTAccessClaims{StandardClaims: jwt.StandardClaims{
+				Subject: "user", ExpiresAt: 1700000000, IssuedAt: 1699999000, NotBefore: 1699999000,
+			}},
+		},
+		{
+			"audience array",
+			jwt.MapClaims{"aud": []interface{}{"api", "other"}, "iss": "issuer", "jti": "id"},
+			&claims.JWTAccessClaims{StandardClaims: jwt.StandardClaims{Audience: "api", Issuer: "issuer", Id: "id"}},
+		},
+		{
+			"access claims",
+			jwt.MapClaims{"aud": "api", "client_id": "client", "scope": "openid profile", "roles": []interface{}{"admin", "user"}, "custom": true},
+			&claims.JWTAccessClaims{
+				StandardClaims: jwt.StandardClaims{Audience: "api"},
+				ClientId:       "client",
+				Scope:          "openid profile",
+				Roles:          []string{"admin", "user"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verify := func(string) (jwt.MapClaims, error) { return tt.mapClaims, nil }
+			var got *claims.JWTAccessClaims
+			h := Middleware(verify, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got, _ = AccessClaimsFromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Authorization", "Bearer token")
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, body %s", rec.Code, rec.Body.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("access claims = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMiddlewareRejectsInvalidClaimTypes(t *testing.T) {
+	for _, mapClaims := range []jwt.MapClaims{
+		{"sub": 42},
+		{"aud": 42},
+		{"aud": []interface{}{42}},
+		{"roles": "admin"},
+		{"exp": "tomorrow"},
+	} {
+		verify := func(string) (jwt.MapClaims, error) { return mapClaims, nil }
+		h := Middleware(verify, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("claims %v were accepted", mapClaims)
+		}))
+
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Authorization", "Bearer token")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("claims %v: status = %d, want %d", mapClaims, rec.Code, http.StatusUnauthorized)
+		}
+	}
+}
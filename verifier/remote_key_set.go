@@ -0,0 +1,210 @@
+package verifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Ashik80/oauth2jwtgen/jwk"
+)
+
+// Largest key set or discovery document that is read
+const maxRemoteDocumentSize = 1 << 20
+
+type RemoteKeySetOptions struct {
+	// Client that fetches the key set. A client with a 10 second timeout is used when it is nil.
+	Client *http.Client
+	// How long the key set is cached when the response has no Cache-Control max-age. Defaults to 5 minutes.
+	DefaultMaxAge time.Duration
+	// Least time between two fetches, so that tokens with unknown kids can not make every request
+	// fetch the key set. Defaults to 30 seconds.
+	MinRefreshInterval time.Duration
+}
+
+// Key set fetched from a JWKS endpoint. It is cached as long as the Cache-Control header of the
+// response allows and fetched again when it expires or a token has a kid that it does not contain.
+// It is safe for concurrent use and implements KeyResolver.
+type RemoteKeySet struct {
+	url                string
+	client             *http.Client
+	defaultMaxAge      time.Duration
+	minRefreshInterval time.Duration
+	now                func() time.Time
+
+	// Held while the key set is fetched, so that concurrent misses fetch it once
+	fetchMu     sync.Mutex
+	lastAttempt time.Time
+	lastErr     error
+
+	mu        sync.RWMutex
+	set       *jwk.Set
+	expiresAt time.Time
+}
+
+type discoveryDocument struct {
+	Issuer  string `json:"issuer"`
+	JWKSUri string `json:"jwks_uri"`
+}
+
+// Creates a key set that is fetched from jwksURL the first time a key is resolved
+func NewRemoteKeySet(jwksURL string, opt *RemoteKeySetOptions) *RemoteKeySet {
+	if opt == nil {
+		opt = &RemoteKeySetOptions{}
+	}
+	s := &RemoteKeySet{
+		url:                jwksURL,
+		client:             opt.Client,
+		defaultMaxAge:      opt.DefaultMaxAge,
+		minRefreshInterval: opt.MinRefreshInterval,
+		now:                time.Now,
+	}
+	if s.client == nil {
+		s.client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if s.defaultMaxAge <= 0 {
+		s.defaultMaxAge = 5 * time.Minute
+	}
+	if s.minRefreshInterval <= 0 {
+		s.minRefreshInterval = 30 * time.Second
+	}
+	return s
+}
+
+// Reads the jwks_uri of the issuer from its OpenID Connect discovery document and creates a key set for it
+func DiscoverKeySet(ctx context.Context, issuer string, opt *RemoteKeySetOptions) (*RemoteKeySet, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	if opt != nil && opt.Client != nil {
+		client = opt.Client
+	}
+	configURL := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+	resp, err := get(ctx, client, configURL)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching discovery document: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var doc discoveryDocument
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxRemoteDocumentSize)).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid discovery document: %w", err)
+	}
+	// OpenID Connect Discovery 1.0 section 4.3
+	if doc.Issuer != issuer {
+		return nil, fmt.Errorf("discovery document is for issuer %q, expected %q", doc.Issuer, issuer)
+	}
+	if doc.JWKSUri == "" {
+		return nil, fmt.Errorf("discovery document has no jwks_uri")
+	}
+	return NewRemoteKeySet(doc.JWKSUri, opt), nil
+}
+
+// Returns the key with the kid, fetching the key set when the cached one expired or does not
+// have the kid. A stale key set is used while the endpoint can not be reached.
+func (s *RemoteKeySet) ResolveKey(kid string) (interface{}, string, error) {
+	set, fresh := s.cached()
+	if set != nil && fresh {
+		if _, ok := set.Key(kid); ok {
+			return resolveFromSet(set, kid)
+		}
+	}
+
+	err := s.refresh(context.Background(), false)
+	if set, _ = s.cached(); set == nil {
+		return nil, "", err
+	}
+	if _, ok := set.Key(kid); !ok && err != nil {
+		return nil, "", err
+	}
+	return resolveFromSet(set, kid)
+}
+
+// Fetches the key set now, regardless of the cache and the refresh interval
+func (s *RemoteKeySet) Refresh(ctx context.Context) error {
+	return s.refresh(ctx, true)
+}
+
+// Returns the cached key set and whether it has not expired yet
+func (s *RemoteKeySet) cached() (*jwk.Set, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.set, s.now().Before(s.expiresAt)
+}
+
+func (s *RemoteKeySet) refresh(ctx context.Context, force bool) error {
+	s.fetchMu.Lock()
+	defer s.fetchMu.Unlock()
+
+	now := s.now()
+	// The goroutines that waited for a fetch use its result instead of fetching again
+	if !force && now.Sub(s.lastAttempt) < s.minRefreshInterval {
+		return s.lastErr
+	}
+	s.lastAttempt = now
+	s.lastErr = s.fetch(ctx)
+	return s.lastErr
+}
+
+func (s *RemoteKeySet) fetch(ctx context.Context) error {
+	resp, err := get(ctx, s.client, s.url)
+	if err != nil {
+		return fmt.Errorf("error fetching key set: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var set jwk.Set
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxRemoteDocumentSize)).Decode(&set); err != nil {
+		return fmt.Errorf("invalid key set: %w", err)
+	}
+	maxAge, ok := cacheMaxAge(resp.Header.Get("Cache-Control"))
+	if !ok {
+		maxAge = s.defaultMaxAge
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.set = &set
+	s.expiresAt = s.now().Add(maxAge)
+	return nil
+}
+
+func get(ctx context.Context, client *http.Client, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status %s from %s", resp.Status, url)
+	}
+	return resp, nil
+}
+
+// Returns how long a response may be cached according to its Cache-Control header.
+// False is returned when the header does not say.
+func cacheMaxAge(cacheControl string) (time.Duration, bool) {
+	maxAge, found := time.Duration(0), false
+	for _, directive := range strings.Split(cacheControl, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "no-store", "no-cache":
+			return 0, true
+		case "max-age":
+			seconds, err := strconv.Atoi(strings.Trim(value, `"`))
+			if err != nil || seconds < 0 {
+				continue
+			}
+			maxAge, found = time.Duration(seconds)*time.Second, true
+		}
+	}
+	return maxAge, found
+}
@@ -0,0 +1,361 @@
+package verifier
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Ashik80/oauth2jwtgen/jwk"
+)
+
+// JWKS endpoint whose keys, Cache-Control header and availability can be changed by the test
+type keySetServer struct {
+	*httptest.Server
+	fetches int32
+
+	mu           sync.Mutex
+	set          jwk.Set
+	cacheControl string
+	down         bool
+	// Closed by the test to let requests finish when it is not nil
+	release chan struct{}
+	// Receives a value when the first request arrives when it is not nil
+	arrived chan struct{}
+}
+
+func newKeySetServer(t *testing.T, cacheControl string, kids ...string) *keySetServer {
+	t.Helper()
+	s := &keySetServer{cacheControl: cacheControl}
+	s.setKeys(t, kids...)
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&s.fetches, 1)
+		s.mu.Lock()
+		set, cacheControl, down := s.set, s.cacheControl, s.down
+		release, arrived := s.release, s.arrived
+		s.mu.Unlock()
+
+		if arrived != nil {
+			select {
+			case arrived <- struct{}{}:
+			default:
+			}
+		}
+		if release != nil {
+			<-release
+		}
+		if down {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		if cacheControl != "" {
+			w.Header().Set("Cache-Control", cacheControl)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(set)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *keySetServer) setKeys(t *testing.T, kids ...string) {
+	t.Helper()
+	var set jwk.Set
+	for _, kid := range kids {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatalf("GenerateKey: %v", err)
+		}
+		k, err := jwk.FromPublicKey(kid, "ES256", &key.PublicKey)
+		if err != nil {
+			t.Fatalf("FromPublicKey: %v", err)
+		}
+		set.Keys = append(set.Keys, *k)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.set = set
+}
+
+func (s *keySetServer) setDown(down bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.down = down
+}
+
+func (s *keySetServer) fetchCount() int {
+	return int(atomic.LoadInt32(&s.fetches))
+}
+
+// Clock that only moves when the test advances it
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestKeySet(url string) (*RemoteKeySet, *testClock) {
+	clock := &testClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	s := NewRemoteKeySet(url, nil)
+	s.now = clock.Now
+	return s, clock
+}
+
+func TestCacheMaxAge(t *testing.T) {
+	tests := []struct {
+		cacheControl string
+		want         time.Duration
+		wantOk       bool
+	}{
+		{"", 0, false},
+		{"public", 0, false},
+		{"max-age=60", time.Minute, true},
+		{"public, max-age=120", 2 * time.Minute, true},
+		{`max-age="30"`, 30 * time.Second, true},
+		{"max-age=invalid", 0, false},
+		{"max-age=-1", 0, false},
+		{"no-cache", 0, true},
+		{"no-store", 0, true},
+		{"max-age=60, no-cache", 0, true},
+		{"No-Store, max-age=60", 0, true},
+	}
+	for _, tt := range tests {
+		got, ok := cacheMaxAge(tt.cacheControl)
+		if got != tt.want || ok != tt.wantOk {
+			t.Errorf("cacheMaxAge(%q) = %v, %v, want %v, %v", tt.cacheControl, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
+
+func TestRemoteKeySetFollowsCacheControl(t *testing.T) {
+	tests := []struct {
+		name         string
+		cacheControl string
+		elapsed      time.Duration
+		wantFetches  int
+	}{
+		{"within max-age", "max-age=60", 45 * time.Second, 1},
+		{"after max-age", "max-age=60", 61 * time.Second, 2},
+		{"no-cache", "no-cache", 45 * time.Second, 2},
+		{"no-store", "no-store", 45 * time.Second, 2},
+		{"within default max age", "", 4 * time.Minute, 1},
+		{"after default max age", "", 6 * time.Minute, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newKeySetServer(t, tt.cacheControl, "k1")
+			s, clock := newTestKeySet(srv.URL)
+
+			if _, _, err := s.ResolveKey("k1"); err != nil {
+				t.Fatalf("ResolveKey: %v", err)
+			}
+			clock.advance(tt.elapsed)
+			if _, _, err := s.ResolveKey("k1"); err != nil {
+				t.Fatalf("ResolveKey: %v", err)
+			}
+			if got := srv.fetchCount(); got != tt.wantFetches {
+				t.Errorf("fetches = %d, want %d", got, tt.wantFetches)
+			}
+		})
+	}
+}
+
+func TestRemoteKeySetFetchesAgainForUnknownKid(t *testing.T) {
+	srv := newKeySetServer(t, "max-age=300", "k1")
+	s, clock := newTestKeySet(srv.URL)
+
+	if _, _, err := s.ResolveKey("k1"); err != nil {
+		t.Fatalf("ResolveKey(k1): %v", err)
+	}
+
+	// The issuer rotated to a new key while the cached set is still fresh
+	srv.setKeys(t, "k1", "k2")
+	clock.advance(time.Minute)
+	if _, alg, err := s.ResolveKey("k2"); err != nil || alg != "ES256" {
+		t.Fatalf("ResolveKey(k2) = %q, %v, want ES256", alg, err)
+	}
+	if got := srv.fetchCount(); got != 2 {
+		t.Errorf("fetches = %d, want 2", got)
+	}
+	// Known kids are served from the cache
+	if _, _, err := s.ResolveKey("k1"); err != nil {
+		t.Fatalf("ResolveKey(k1): %v", err)
+	}
+	if got := srv.fetchCount(); got != 2 {
+		t.Errorf("fetches = %d, want 2", got)
+	}
+}
+
+func TestRemoteKeySetRateLimitsRefreshes(t *testing.T) {
+	srv := newKeySetServer(t, "max-age=300", "k1")
+	s, clock := newTestKeySet(srv.URL)
+
+	for i := 0; i < 10; i++ {
+		if _, _, err := s.ResolveKey("unknown"); !errors.Is(err, ErrUnknownKey) {
+			t.Fatalf("ResolveKey(unknown) error = %v, want ErrUnknownKey", err)
+		}
+	}
+	if got := srv.fetchCount(); got != 1 {
+		t.Errorf("fetches after unknown kids = %d, want 1", got)
+	}
+
+	clock.advance(29 * time.Second)
+	s.ResolveKey("unknown")
+	if got := srv.fetchCount(); got != 1 {
+		t.Errorf("fetches before MinRefreshInterval = %d, want 1", got)
+	}
+
+	clock.advance(time.Second)
+	s.ResolveKey("unknown")
+	if got := srv.fetchCount(); got != 2 {
+		t.Errorf("fetches after MinRefreshInterval = %d, want 2", got)
+	}
+
+	// Refresh ignores the interval
+	if err := s.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if got := srv.fetchCount(); got != 3 {
+		t.Errorf("fetches after Refresh = %d, want 3", got)
+	}
+}
+
+func TestRemoteKeySetUsesStaleSetWhileServerIsDown(t *testing.T) {
+	srv := newKeySetServer(t, "max-age=60", "k1")
+	s, clock := newTestKeySet(srv.URL)
+
+	if _, _, err := s.ResolveKey("k1"); err != nil {
+		t.Fatalf("ResolveKey: %v", err)
+	}
+
+	srv.setDown(true)
+	clock.advance(2 * time.Minute)
+	if _, _, err := s.ResolveKey("k1"); err != nil {
+		t.Fatalf("ResolveKey with stale set: %v", err)
+	}
+	if got := srv.fetchCount(); got != 2 {
+		t.Errorf("fetches = %d, want 2", got)
+	}
+
+	// Kids missing from the stale set report why the set could not be fetched
+	clock.advance(time.Minute)
+	_, _, err := s.ResolveKey("k2")
+	if err == nil || errors.Is(err, ErrUnknownKey) {
+		t.Errorf("ResolveKey(k2) error = %v, want the fetch error", err)
+	}
+
+	srv.setDown(false)
+	clock.advance(time.Minute)
+	if _, _, err := s.ResolveKey("k1"); err != nil {
+		t.Fatalf("ResolveKey after recovery: %v", err)
+	}
+}
+
+func TestRemoteKeySetFailsWithoutAnySet(t *testing.T) {
+	srv := newKeySetServer(t, "", "k1")
+	srv.setDown(true)
+	s, _ := newTestKeySet(srv.URL)
+
+	if _, _, err := s.ResolveKey("k1"); err == nil {
+		t.Fatal("ResolveKey succeeded while the server is down")
+	}
+}
+
+func TestRemoteKeySetConcurrentMissesShareOneFetch(t *testing.T) {
+	srv := newKeySetServer(t, "", "k1")
+	srv.release = make(chan struct{})
+	srv.arrived = make(chan struct{}, 1)
+	s, _ := newTestKeySet(srv.URL)
+
+	const n = 10
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, _, err := s.ResolveKey("k1")
+			errs <- err
+		}()
+	}
+
+	<-srv.arrived
+	close(srv.release)
+	for i := 0; i < n; i++ {
+		if err := <-errs; err != nil {
+			t.Errorf("ResolveKey: %v", err)
+		}
+	}
+	if got := srv.fetchCount(); got != 1 {
+		t.Errorf("fetches = %d, want 1", got)
+	}
+}
+
+func TestDiscoverKeySet(t *testing.T) {
+	jwks := newKeySetServer(t, "", "k1")
+
+	tests := []struct {
+		name string
+		// Issuer in the discovery document, the URL of the server when empty
+		docIssuer string
+		// Suffix added to the URL of the server to make the issuer that is discovered
+		issuerSuffix string
+		noJWKSUri    bool
+		wantErr      bool
+	}{
+		{name: "matching issuer"},
+		{name: "other issuer", docIssuer: "https://attacker.example.com", wantErr: true},
+		{name: "trailing slash", issuerSuffix: "/", wantErr: true},
+		{name: "no jwks_uri", noJWKSUri: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var srv *httptest.Server
+			srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/.well-known/openid-configuration" {
+					http.NotFound(w, r)
+					return
+				}
+				doc := discoveryDocument{Issuer: tt.docIssuer, JWKSUri: jwks.URL}
+				if doc.Issuer == "" {
+					doc.Issuer = srv.URL
+				}
+				if tt.noJWKSUri {
+					doc.JWKSUri = ""
+				}
+				json.NewEncoder(w).Encode(doc)
+			}))
+			defer srv.Close()
+
+			s, err := DiscoverKeySet(context.Background(), srv.URL+tt.issuerSuffix, nil)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("DiscoverKeySet succeeded")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DiscoverKeySet: %v", err)
+			}
+			if _, _, err := s.ResolveKey("k1"); err != nil {
+				t.Errorf("ResolveKey: %v", err)
+			}
+		})
+	}
+}
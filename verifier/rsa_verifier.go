@@ -2,33 +2,20 @@ package verifier
 
 import (
 	"crypto/rsa"
-	"crypto/x509"
-	"encoding/pem"
 	"fmt"
-	"os"
 
 	"github.com/golang-jwt/jwt"
 )
 
 func LoadRSAPublicKeyFromFile(filePath string) (*rsa.PublicKey, error) {
-	pubKeyFile, err := os.ReadFile(filePath)
+	pubKey, format, err := loadPublicKeyFromFile(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %v", err)
-	}
-
-	block, _ := pem.Decode(pubKeyFile)
-	if block == nil || block.Type != "PUBLIC KEY" {
-		return nil, fmt.Errorf("failed to parse PEM block containing the public key")
-	}
-
-	pubKey, err := x509.ParsePKIXPublicKey(block.Bytes)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse public key: %v", err)
+		return nil, err
 	}
 
 	rsaPubKey, ok := pubKey.(*rsa.PublicKey)
 	if !ok {
-		return nil, fmt.Errorf("not an RSA public key")
+		return nil, fmt.Errorf("%s key is not an RSA public key", format)
 	}
 
 	return rsaPubKey, nil
@@ -41,7 +28,7 @@ func VerifyRSToken(tokenString string, filePath string) (jwt.MapClaims, error) {
 	}
 	token, err := ParseRSToken(tokenString, publicKey)
 	if err != nil {
-		return nil, fmt.Errorf("error parsing token: %v", err)
+		return nil, fmt.Errorf("error parsing token: %w", err)
 	}
 
 	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
@@ -0,0 +1,261 @@
+package verifier
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Ashik80/oauth2jwtgen/store"
+	"github.com/golang-jwt/jwt"
+)
+
+type VerifierOptions struct {
+	// Accepted values of the iss claim. Any issuer is accepted when it is empty.
+	Issuers []string
+	// Value that the aud claim must contain. The audience is not checked when it is empty.
+	Audience string
+	// Clock skew allowed when exp, nbf and iat are checked
+	Leeway time.Duration
+	// Rejects tokens issued longer ago than MaxAge. Tokens must have an iat claim when it is set.
+	MaxAge time.Duration
+	// Claims that must be present and not empty
+	RequiredClaims []string
+	// Accepted signing algorithms. When it is empty the algorithms that fit the key are accepted.
+	Algorithms []string
+	// Rejects tokens whose jti was revoked, such as the access tokens revoked at the revocation
+	// endpoint of the server. Revocation is not checked when it is nil.
+	RevokedTokenStore store.RevokedTokenStore
+}
+
+// Verifies the signature and the claims of tokens. Its Verify method can be passed to Middleware.
+type Verifier struct {
+	resolver KeyResolver
+	options  VerifierOptions
+	now      func() time.Time
+}
+
+// Creates a verifier that checks signatures with key, which is an HMAC secret as a string or
+// []byte, or an RSA, ECDSA or Ed25519 public key.
+func NewVerifier(key interface{}, opt *VerifierOptions) (*Verifier, error) {
+	if secret, ok := key.(string); ok {
+		key = []byte(secret)
+	}
+	if len(KeyAlgorithms(key)) == 0 {
+		return nil, fmt.Errorf("unsupported key type %T", key)
+	}
+	return NewKeyVerifier(staticKey{key}, opt), nil
+}
+
+// Creates a verifier that resolves the key of each token from the kid in its header, so that
+// tokens signed by any key of a manager or key set can be verified
+func NewKeyVerifier(resolver KeyResolver, opt *VerifierOptions) *Verifier {
+	v := &Verifier{
+		resolver: resolver,
+		now:      time.Now,
+	}
+	if opt != nil {
+		v.options = *opt
+	}
+	return v
+}
+
+// Returns the signing algorithms that can be verified with the key
+func KeyAlgorithms(key interface{}) []string {
+	switch key.(type) {
+	case []byte:
+		return []string{"HS256", "HS384", "HS512"}
+	case *rsa.PublicKey:
+		return []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512"}
+	case *ecdsa.PublicKey:
+		return []string{"ES256", "ES384", "ES512"}
+	case ed25519.PublicKey:
+		return []string{"EdDSA"}
+	}
+	return nil
+}
+
+// Returns the claims of the token when its signature and claims are valid
+func (v *Verifier) Verify(tokenString string) (jwt.MapClaims, error) {
+	parser := &jwt.Parser{SkipClaimsValidation: true}
+	token, err := parser.Parse(tokenString, v.Keyfunc)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	mapClaims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, ErrMalformed
+	}
+	if err := v.validateClaims(mapClaims); err != nil {
+		return nil, err
+	}
+	if err := v.checkRevoked(mapClaims); err != nil {
+		return nil, err
+	}
+	return mapClaims, nil
+}
+
+// Resolves the key of the token and rejects the token unless its algorithm is allowed by the
+// options and fits the key. A key that is pinned to an algorithm allows that algorithm only.
+// It can be passed to jwt.Parse when the claims are checked differently, for example to accept
+// expired tokens.
+func (v *Verifier) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, keyAlg, err := v.resolver.ResolveKey(kid)
+	if err != nil {
+		return nil, err
+	}
+	keyAlgs := KeyAlgorithms(key)
+	if keyAlg != "" {
+		if !slices.Contains(keyAlgs, keyAlg) {
+			return nil, fmt.Errorf("%w: key %s is pinned to %s which does not fit the key", ErrAlgorithmNotAllowed, kid, keyAlg)
+		}
+		keyAlgs = []string{keyAlg}
+	}
+
+	alg := token.Method.Alg()
+	if len(v.options.Algorithms) > 0 && !slices.Contains(v.options.Algorithms, alg) {
+		return nil, fmt.Errorf("%w: %s", ErrAlgorithmNotAllowed, alg)
+	}
+	if !slices.Contains(keyAlgs, alg) {
+		return nil, fmt.Errorf("%w: %s can not be used with the key", ErrAlgorithmNotAllowed, alg)
+	}
+	return key, nil
+}
+
+func (v *Verifier) validateClaims(c jwt.MapClaims) error {
+	now := v.now()
+	leeway := v.options.Leeway
+
+	for _, name := range v.options.RequiredClaims {
+		if value, ok := c[name]; !ok || value == nil || value == "" {
+			return fmt.Errorf("%w: %s", ErrMissingClaim, name)
+		}
+	}
+
+	exp, hasExp, err := timeClaim(c, "exp")
+	if err != nil {
+		return err
+	}
+	if hasExp && now.After(exp.Add(leeway)) {
+		return fmt.Errorf("%w: expired at %s", ErrExpired, exp.UTC().Format(time.RFC3339))
+	}
+
+	nbf, hasNbf, err := timeClaim(c, "nbf")
+	if err != nil {
+		return err
+	}
+	if hasNbf && now.Add(leeway).Before(nbf) {
+		return fmt.Errorf("%w: valid from %s", ErrNotYetValid, nbf.UTC().Format(time.RFC3339))
+	}
+
+	iat, hasIat, err := timeClaim(c, "iat")
+	if err != nil {
+		return err
+	}
+	if hasIat && now.Add(leeway).Before(iat) {
+		return fmt.Errorf("%w: issued at %s", ErrNotYetValid, iat.UTC().Format(time.RFC3339))
+	}
+	if v.options.MaxAge > 0 {
+		if !hasIat {
+			return fmt.Errorf("%w: iat", ErrMissingClaim)
+		}
+		if now.Sub(iat) > v.options.MaxAge+leeway {
+			return fmt.Errorf("%w: issued at %s", ErrTooOld, iat.UTC().Format(time.RFC3339))
+		}
+	}
+
+	if len(v.options.Issuers) > 0 {
+		iss, _ := c["iss"].(string)
+		if !slices.Contains(v.options.Issuers, iss) {
+			return fmt.Errorf("%w: %q", ErrWrongIssuer, iss)
+		}
+	}
+
+	if v.options.Audience != "" && !hasAudience(c, v.options.Audience) {
+		return fmt.Errorf("%w: %v", ErrWrongAudience, c["aud"])
+	}
+	return nil
+}
+
+// Fails when the jti of the token was revoked. Tokens are rejected when the store can not be asked.
+func (v *Verifier) checkRevoked(c jwt.MapClaims) error {
+	if v.options.RevokedTokenStore == nil {
+		return nil
+	}
+	jti, _ := c["jti"].(string)
+	if jti == "" {
+		return nil
+	}
+	revoked, err := v.options.RevokedTokenStore.IsAccessTokenRevoked(context.Background(), jti)
+	if err != nil {
+		return fmt.Errorf("failed to check token revocation: %w", err)
+	}
+	if revoked {
+		return fmt.Errorf("%w: %s", ErrRevoked, jti)
+	}
+	return nil
+}
+
+// Maps the errors of the jwt parser to the errors of this package
+func parseError(err error) error {
+	var vErr *jwt.ValidationError
+	if !errors.As(err, &vErr) {
+		return fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	switch {
+	case vErr.Errors&jwt.ValidationErrorMalformed != 0:
+		return fmt.Errorf("%w: %v", ErrMalformed, err)
+	case vErr.Errors&jwt.ValidationErrorUnverifiable != 0 && vErr.Inner != nil:
+		// Returned by the key function of the verifier
+		return vErr.Inner
+	case vErr.Errors&jwt.ValidationErrorUnverifiable != 0:
+		// The jwt package does not know the algorithm
+		return fmt.Errorf("%w: %v", ErrAlgorithmNotAllowed, err)
+	case vErr.Errors&jwt.ValidationErrorSignatureInvalid != 0:
+		return fmt.Errorf("%w: %v", ErrBadSignature, err)
+	}
+	return fmt.Errorf("%w: %v", ErrMalformed, err)
+}
+
+// Returns the time of a NumericDate claim
+func timeClaim(c jwt.MapClaims, name string) (time.Time, bool, error) {
+	value, ok := c[name]
+	if !ok {
+		return time.Time{}, false, nil
+	}
+	var seconds float64
+	switch n := value.(type) {
+	case float64:
+		seconds = n
+	case json.Number:
+		f, err := n.Float64()
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("%w: %s is not a number", ErrMalformed, name)
+		}
+		seconds = f
+	default:
+		return time.Time{}, false, fmt.Errorf("%w: %s is not a number", ErrMalformed, name)
+	}
+	return time.Unix(int64(seconds), 0), true, nil
+}
+
+// Reports whether the aud claim, a string or an array of strings, contains the audience
+func hasAudience(c jwt.MapClaims, audience string) bool {
+	switch aud := c["aud"].(type) {
+	case string:
+		return aud == audience
+	case []interface{}:
+		for _, a := range aud {
+			if s, ok := a.(string); ok && s == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package verifier
+
+import (
+	"fmt"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// Parses the token and rejects it unless it was signed with the given signing method
+func ParseTokenWithMethod(tokenString string, signingKey interface{}, method jwt.SigningMethod) (*jwt.Token, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if token.Method.Alg() != method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return signingKey, nil
+	})
+	return token, err
+}
+
+func VerifyHSTokenWithMethod(tokenString string, signingKey string, method jwt.SigningMethod) (jwt.MapClaims, error) {
+	if _, ok := method.(*jwt.SigningMethodHMAC); !ok {
+		return nil, fmt.Errorf("signing method %s can not be used with HMAC keys", method.Alg())
+	}
+	token, err := ParseTokenWithMethod(tokenString, []byte(signingKey), method)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing token: %w", err)
+	}
+	return validClaims(token)
+}
+
+// Verifies tokens signed with RS256, RS384, RS512, PS256, PS384 or PS512
+func VerifyRSTokenWithMethod(tokenString string, filePath string, method jwt.SigningMethod) (jwt.MapClaims, error) {
+	switch method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+	default:
+		return nil, fmt.Errorf("signing method %s can not be used with RSA keys", method.Alg())
+	}
+	publicKey, err := LoadRSAPublicKeyFromFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	token, err := ParseTokenWithMethod(tokenString, publicKey, method)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing token: %w", err)
+	}
+	return validClaims(token)
+}
+
+func validClaims(token *jwt.Token) (jwt.MapClaims, error) {
+	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
+		return claims, nil
+	}
+	return nil, fmt.Errorf("invalid token or claim")
+}